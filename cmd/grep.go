@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"gofs/internal/cli"
+
+	"github.com/spf13/cobra"
+)
+
+// grepCmd searches file contents, like running gofs with --contains and --show-lines
+var grepCmd = &cobra.Command{
+	Use:     "grep <regex> [pathname...]",
+	Short:   "Search file contents and print the matching lines",
+	Long:    `Search the contents of every file gofs finds and print the matching lines. Use -g to restrict the files searched by name.`,
+	Args:    cobra.MinimumNArgs(1),
+	PreRunE: cli.PrioritizeHelpAndVersion,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.Flags().Set("contains", args[0])
+		if !cmd.Flags().Changed("show-lines") {
+			cmd.Flags().Set("show-lines", "true")
+		}
+
+		// Without --glob, every file name matches
+		searchArgs := args[1:]
+		if globPattern, _ := cmd.Flags().GetString("glob"); globPattern == "" {
+			searchArgs = append([]string{"."}, searchArgs...)
+		}
+
+		return runSearch(cmd, searchArgs)
+	},
+}
+
+func init() {
+	cli.DefineFlags(grepCmd)
+}
@@ -1,73 +1,143 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"gofs/internal/cli"
-	"gofs/internal/filter"
 	"gofs/internal/output"
-	"gofs/internal/search"
-	"gofs/internal/traverse"
+	"gofs/internal/output/formats"
+	"gofs/pkg/gofs"
 	"gofs/utils"
+	"os"
 
 	"github.com/spf13/cobra"
 )
 
 // Root command for the CLI
 var rootCmd = &cobra.Command{
-	Use:     "gofs <pattern> [pathname]",
+	Use:     "gofs <pattern> [pathname...]",
 	Short:   "gofs is a lightweight CLI tool for searching files.",
 	Long:    `A program to find files and directories in your filesystem.`,
+	Args:    cobra.ArbitraryArgs, // Positional arguments are patterns and pathnames, not subcommands
 	PreRunE: cli.PrioritizeHelpAndVersion,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		// Step 1: Validate command
-		err := utils.ValidateCommand(cmd, args)
-		if err != nil {
-			return err // Command validation errors are returned to Cobra
-		}
+	RunE:    runSearch,
+}
 
-		// Step 2: Parse flags and arguments into a Config struct
-		config := cli.ParseFlags(cmd, args)
+// runSearch runs the search pipeline for a pattern and its pathnames
+func runSearch(cmd *cobra.Command, args []string) error {
+	// Step 1: Validate command
+	err := utils.ValidateCommand(cmd, args)
+	if err != nil {
+		return err // Command validation errors are returned to Cobra
+	}
 
-		// Step 3: Perform traversal and pathname validation
-		traversalResults, err := traverse.TraverseAndValidate(config.Root, config.Pathname, config.Depth, config.MaxThreads)
-		if err != nil {
-			return err // Handle traversal or pathname validation errors
-		}
+	// Step 2: Parse flags and arguments into a Config struct
+	config := cli.ParseFlags(cmd, args)
 
-		// Step 4: Perform pattern check and validation
-		effectivePattern, err := utils.HandlePattern(config.Pattern, config.GlobPattern)
-		if err != nil {
-			return fmt.Errorf("error determining pattern: %v", err)
-		}
+	// Step 3: Validate the options and set up the search
+	opts, err := finderOptions(config)
+	if err != nil {
+		return err
+	}
+	finder, err := gofs.New(opts)
+	if err != nil {
+		return err
+	}
 
-		// Step 5: Perform search (regex/common-string or glob) on traversalResults
-		var searchResults []string
-		searchResults, err = search.SearchPattern(effectivePattern, traversalResults, config.MaxThreads, config.GlobPattern != "")
-		if err != nil {
-			return fmt.Errorf("error during search: %v", err)
+	// Step 4: Set up the active formatters, in their registered order
+	if config.Print.NullSeparated {
+		if config.Formats.Format != output.FormatText {
+			return fmt.Errorf("--print0 cannot be combined with --format %s", config.Formats.Format)
 		}
+		config.Formats.Quote = formats.QuoteLiteral // Names must reach xargs -0 unchanged
+	}
+	config.Formats.Hyperlink, err = cli.ResolveHyperlink(config.Print)
+	if err != nil {
+		return err
+	}
+	formatters, err := output.BuildFormatters(config.Formats)
+	if err != nil {
+		return fmt.Errorf("error formatting results: %v", err)
+	}
+	printer, err := cli.NewPrinter(config.Print)
+	if err != nil {
+		return err
+	}
 
-		// Step 6: Apply filters if any active FilterOptions are provided
-		if utils.HasActiveFilters(config.FilterOptions) {
-			searchResults, err = filter.FilterResults(searchResults, config.FilterOptions)
-			if err != nil {
-				return fmt.Errorf("error during filtering: %v", err)
-			}
+	// Every stage below streams into the next one; cancelling stops the whole pipeline
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Step 5: Start the search
+	found, searchErr := finder.Stream(ctx)
+	results := cli.Entries(ctx, found)
+
+	if config.Formats.Format != output.FormatText {
+		// Steps 6 and 7: Print a JSON record for every result as it arrives
+		if err := output.WriteJSON(ctx, os.Stdout, results, config.Formats.Format == output.FormatNDJSON); err != nil {
+			return err
 		}
+	} else {
+		// Step 6: Format the search results
+		formattedResults := output.FormatResults(ctx, results, formatters)
+
+		// Step 7: Print the results as they arrive
+		printer.PrintResults(formattedResults)
+	}
+
+	// Step 8: Report any error hit during the search
+	if err := <-searchErr; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// finderOptions translates the command line configuration into library options
+func finderOptions(config cli.Config) (gofs.Options, error) {
+	// -d counts levels below the roots from 0, the library from 1
+	depth, err := utils.ValidateDepth(config.Depth)
+	if err != nil {
+		return gofs.Options{}, err
+	}
 
-		// Step 7: Format the search results based on the FormatOptions
-		if utils.HasActiveFormats(config.FormatOptions) {
-			searchResults = output.FormatResults(searchResults, config.FormatOptions)
-			if err != nil {
-				return fmt.Errorf("error during formatting: %v", err)
-			}
+	var maxFileSize int64
+	if config.MaxFileSize != "" {
+		size, err := utils.ParseSize(config.MaxFileSize)
+		if err != nil {
+			return gofs.Options{}, fmt.Errorf("error during content search: %v", err)
 		}
+		maxFileSize = size
+	}
 
-		// Step x: Print the results
-		cli.PrintResults(searchResults)
+	// The library has no "." pattern, an empty one matches everything
+	pattern := config.Pattern
+	if config.GlobPattern != "" || pattern == "." {
+		pattern = ""
+	}
 
-		return nil
-	},
+	return gofs.Options{
+		Roots:         config.Roots,
+		Pattern:       pattern,
+		Glob:          config.GlobPattern,
+		FullPath:      config.FullPath,
+		CaseSensitive: config.CaseSensitive,
+		IgnoreCase:    config.IgnoreCase,
+		MaxDepth:      depth + 1,
+		Threads:       config.MaxThreads,
+		Hidden:        config.IncludeHidden,
+		Archives:      config.Archives,
+		Ignore:        config.Ignore,
+		Filters:       config.Filters,
+		Contains:      config.Contains,
+		ShowLines:     config.ShowLines || config.Formats.Format != output.FormatText, // JSON records always list the matched lines
+		ContextLines:  config.Context,
+		MaxFileSize:   maxFileSize,
+		Sort:          config.Sort,
+		OnWarning: func(err error) {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		},
+	}, nil
 }
 
 // Execute runs the root command
@@ -79,4 +149,10 @@ func Execute() {
 
 func init() {
 	cli.DefineFlags(rootCmd)
+
+	// Subcommands would otherwise shadow searches for files named "completion" or "help";
+	// the nameless hidden command replaces cobra's help subcommand, -h still shows help
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.SetHelpCommand(&cobra.Command{Hidden: true})
+	rootCmd.AddCommand(grepCmd)
 }
@@ -2,7 +2,10 @@ module gofs
 
 go 1.23.4
 
-require github.com/spf13/cobra v1.8.1
+require (
+	github.com/spf13/cobra v1.8.1
+	golang.org/x/sys v0.35.0
+)
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
@@ -0,0 +1,229 @@
+// Package archive lets a search descend into zip and tar archives as if they were directories.
+// Members are addressed by the path of their archive, a "!" and their path inside it,
+// e.g. "dist/release.tar.gz!/bin/app". Archives nested in archives work the same way.
+package archive
+
+import (
+	"archive/tar"
+	"errors"
+	"gofs/internal/fsys"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Separator follows the path of an archive in the paths of its members.
+const Separator = "!"
+
+// maxLoaded caps how many tar archives keep their member contents, in memory or spilled to disk, at once.
+const maxLoaded = 16
+
+// IsArchive reports whether a file name has the extension of a supported archive, case-insensitively.
+func IsArchive(name string) bool {
+	return readerFor(name) != nil
+}
+
+// readerFor returns the function reading the index of an archive, nil for other files.
+func readerFor(name string) func(a *archiveFS, name string) (*index, error) {
+	lower := strings.ToLower(name)
+	switch {
+	case hasSuffix(lower, ".zip"):
+		return readZip
+	case hasSuffix(lower, ".tar"):
+		return readTar(nil)
+	case hasSuffix(lower, ".tar.gz"), hasSuffix(lower, ".tgz"):
+		return readTar(gzipReader)
+	case hasSuffix(lower, ".tar.bz2"):
+		return readTar(bzip2Reader)
+	}
+	return nil
+}
+
+// hasSuffix reports whether name ends with an extension and has a name before it.
+func hasSuffix(name, ext string) bool {
+	return len(name) > len(ext) && strings.HasSuffix(name, ext)
+}
+
+// archiveFS serves paths inside archives from their index and every other path from the wrapped file system.
+type archiveFS struct {
+	base fsys.FS
+
+	mu       sync.Mutex
+	archives map[string]*cached // By archive path
+	loaded   []*tarContents     // Tar archives holding their contents, least recently used first
+	inMemory int64              // Bytes of tar members held in memory, at most maxInMemory
+}
+
+// cached is an archive index, read once on first use.
+type cached struct {
+	once  sync.Once
+	index *index
+	err   error
+}
+
+// NewFS wraps fileSystem so that paths of archive members, like "release.tar.gz!/bin/app", can be
+// read, listed and opened. Archives are read when first used and their listing is kept in memory.
+func NewFS(fileSystem fsys.FS) fsys.FS {
+	return &archiveFS{base: fileSystem, archives: make(map[string]*cached)}
+}
+
+func (a *archiveFS) Native() bool { return a.base.Native() }
+
+func (a *archiveFS) Open(name string) (fs.File, error) {
+	archive, member, ok := split(name)
+	if !ok {
+		return a.base.Open(name)
+	}
+	idx, err := a.index(archive)
+	if err != nil {
+		return nil, err
+	}
+	return idx.open(name, member)
+}
+
+func (a *archiveFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	archive, member, ok := split(name)
+	if !ok {
+		return a.base.ReadDir(name)
+	}
+	idx, err := a.index(archive)
+	if err != nil {
+		return nil, err
+	}
+	return idx.readDir(name, member)
+}
+
+func (a *archiveFS) Stat(name string) (fs.FileInfo, error) {
+	archive, member, ok := split(name)
+	if !ok {
+		return a.base.Stat(name)
+	}
+	idx, err := a.index(archive)
+	if err != nil {
+		return nil, err
+	}
+	return idx.stat(name, member)
+}
+
+// Lstat is the same as Stat inside archives, whose symbolic links are never followed.
+func (a *archiveFS) Lstat(name string) (fs.FileInfo, error) {
+	if _, _, ok := split(name); !ok {
+		return a.base.Lstat(name)
+	}
+	return a.Stat(name)
+}
+
+// ReadLink returns the target of a symbolic link stored in a tar archive.
+func (a *archiveFS) ReadLink(name string) (string, error) {
+	if _, _, ok := split(name); !ok {
+		return a.base.ReadLink(name)
+	}
+	info, err := a.Stat(name)
+	if err != nil {
+		return "", err
+	}
+	if header, ok := info.Sys().(*tar.Header); ok && info.Mode()&fs.ModeSymlink != 0 {
+		return header.Linkname, nil
+	}
+	return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+}
+
+// index returns the index of an archive, reading it on first use.
+func (a *archiveFS) index(archive string) (*index, error) {
+	a.mu.Lock()
+	c, ok := a.archives[archive]
+	if !ok {
+		c = &cached{}
+		a.archives[archive] = c
+	}
+	a.mu.Unlock()
+
+	c.once.Do(func() {
+		c.index, c.err = readerFor(archive)(a, archive)
+		if c.err != nil {
+			c.err = &fs.PathError{Op: "read", Path: archive, Err: c.err}
+		}
+	})
+	return c.index, c.err
+}
+
+// touch records that a tar archive with loaded contents was used, dropping the contents of the
+// least recently used archive beyond maxLoaded. Dropped contents are read again if needed.
+func (a *archiveFS) touch(contents *tarContents) {
+	a.mu.Lock()
+	if i := slices.Index(a.loaded, contents); i >= 0 {
+		a.loaded = slices.Delete(a.loaded, i, i+1)
+	}
+	a.loaded = append(a.loaded, contents)
+	var evicted *tarContents
+	if len(a.loaded) > maxLoaded {
+		evicted = a.loaded[0]
+		a.loaded = a.loaded[1:]
+	}
+	a.mu.Unlock()
+
+	if evicted != nil {
+		a.releaseMemory(evicted.drop())
+	}
+}
+
+// reserveMemory reports whether size more bytes of tar members fit in memory, reserving them if so.
+func (a *archiveFS) reserveMemory(size int64) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.inMemory+size > maxInMemory {
+		return false
+	}
+	a.inMemory += size
+	return true
+}
+
+func (a *archiveFS) releaseMemory(size int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.inMemory -= size
+}
+
+// split separates the path of an archive member into the archive's path and the slash-separated
+// path inside it, "." for the archive's root. It reports false for paths outside archives.
+// The last archive in the path is used, so members of nested archives resolve through their parents.
+func split(name string) (archive, member string, ok bool) {
+	end := len(name)
+	for {
+		i := strings.LastIndex(name[:end], Separator)
+		if i < 0 {
+			return "", "", false
+		}
+		rest := name[i+len(Separator):]
+		if (rest == "" || os.IsPathSeparator(rest[0])) && IsArchive(name[:i]) {
+			member = path.Clean("/" + filepath.ToSlash(rest))[1:]
+			if member == "" {
+				member = "."
+			}
+			return name[:i], member, true
+		}
+		end = i
+	}
+}
+
+// openFile opens an archive through the file system, so archives inside archives can be read too.
+func (a *archiveFS) openFile(name string) (fs.File, fs.FileInfo, error) {
+	file, err := a.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	if !info.Mode().IsRegular() {
+		file.Close()
+		return nil, nil, errors.New("not a regular file")
+	}
+	return file, info, nil
+}
@@ -0,0 +1,174 @@
+package archive
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// index is the listing of an archive, with directories its members imply but do not list.
+type index struct {
+	members map[string]*member // By slash-separated path inside the archive, "." for its root
+	modTime time.Time          // Of the archive, used for implied directories
+
+	// openFile opens a regular member for reading.
+	openFile func(name string, m *member) (fs.File, error)
+}
+
+// member is a file or directory of an archive.
+type member struct {
+	info     fs.FileInfo
+	children map[string]*member // Nil for anything but directories
+}
+
+func newIndex(archive string, modTime time.Time) *index {
+	idx := &index{members: make(map[string]*member), modTime: modTime}
+	idx.members["."] = &member{
+		info:     dirInfo{name: path.Base(archive), modTime: modTime},
+		children: make(map[string]*member),
+	}
+	return idx
+}
+
+// add records a member under its path as stored in the archive. Absolute paths and ".."
+// components are resolved inside the archive; a later member replaces an earlier one.
+func (idx *index) add(name string, info fs.FileInfo) *member {
+	name = path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))[1:]
+	if name == "" {
+		return nil
+	}
+
+	m := &member{info: info}
+	if info.IsDir() {
+		m.children = make(map[string]*member)
+		if existing, ok := idx.members[name]; ok && existing.children != nil {
+			m.children = existing.children // Keep the members found before the directory itself
+		}
+	}
+	idx.members[name] = m
+	idx.dir(path.Dir(name)).children[path.Base(name)] = m
+	return m
+}
+
+// dir returns a directory, creating it and its parents when the archive does not list them.
+func (idx *index) dir(name string) *member {
+	if m, ok := idx.members[name]; ok && m.children != nil {
+		return m
+	}
+	m := &member{info: dirInfo{name: path.Base(name), modTime: idx.modTime}, children: make(map[string]*member)}
+	idx.members[name] = m
+	idx.dir(path.Dir(name)).children[path.Base(name)] = m
+	return m
+}
+
+func (idx *index) stat(name, member string) (fs.FileInfo, error) {
+	m, ok := idx.members[member]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return m.info, nil
+}
+
+func (idx *index) readDir(name, member string) ([]fs.DirEntry, error) {
+	m, ok := idx.members[member]
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	if m.children == nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	return m.entries(), nil
+}
+
+func (idx *index) open(name, member string) (fs.File, error) {
+	m, ok := idx.members[member]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if m.children != nil {
+		return &dirFile{info: m.info, entries: m.entries()}, nil
+	}
+	if !m.info.Mode().IsRegular() {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	file, err := idx.openFile(member, m)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return file, nil
+}
+
+// entries lists a directory's members sorted by name, like os.ReadDir.
+func (m *member) entries() []fs.DirEntry {
+	entries := make([]fs.DirEntry, 0, len(m.children))
+	for _, child := range m.children {
+		entries = append(entries, fs.FileInfoToDirEntry(child.info))
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return entries
+}
+
+// dirInfo describes a directory an archive implies without listing it, or the archive's root.
+type dirInfo struct {
+	name    string
+	modTime time.Time
+}
+
+func (d dirInfo) Name() string       { return d.name }
+func (d dirInfo) Size() int64        { return 0 }
+func (d dirInfo) Mode() fs.FileMode  { return fs.ModeDir | 0o555 }
+func (d dirInfo) ModTime() time.Time { return d.modTime }
+func (d dirInfo) IsDir() bool        { return true }
+func (d dirInfo) Sys() any           { return nil }
+
+// dirFile is an opened directory of an archive.
+type dirFile struct {
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.Name(), Err: fs.ErrInvalid}
+}
+func (d *dirFile) Close() error { return nil }
+
+// ReadDir follows the contract of fs.ReadDirFile.
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(rest))
+	d.offset += n
+	return rest[:n], nil
+}
+
+// memberFile is an opened regular member of an archive.
+type memberFile struct {
+	io.Reader
+	info  fs.FileInfo
+	close func() error // Closes the reader and the archive
+}
+
+func (f *memberFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memberFile) Close() error               { return f.close() }
+
+// bytesFile is a regular member of an archive held in memory. Being an io.ReaderAt,
+// it can itself be read as a zip archive.
+type bytesFile struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+func (f *bytesFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *bytesFile) Close() error               { return nil }
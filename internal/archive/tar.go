@@ -0,0 +1,248 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// Limits on the tar contents held in memory. Other members are spilled to a temporary file.
+const (
+	maxMemberInMemory = 1 << 20  // Largest member kept in memory
+	maxInMemory       = 64 << 20 // Total size of the members kept in memory, over all archives
+)
+
+// tarContents holds the contents of the regular members of a tar archive once they are needed.
+// A tar archive can only be read from the start, so its members are read all at once: small ones
+// into memory, the rest into a temporary file.
+type tarContents struct {
+	mu       sync.Mutex
+	members  map[string]tarMember // By member path, nil until loaded or once dropped
+	spill    *spillFile           // Nil when every member is in memory
+	inMemory int64                // Bytes of the members held in memory
+}
+
+// tarMember is where the contents of a loaded member are: in memory, or in the spill file.
+type tarMember struct {
+	data    []byte
+	spilled bool
+	offset  int64
+	size    int64
+}
+
+// spillFile is a temporary file holding the members of an archive that are not kept in memory.
+// It is closed once it has been dropped and no opened member reads from it anymore.
+type spillFile struct {
+	mu      sync.Mutex
+	file    *os.File
+	removed bool // Removed right after creation, which only systems other than Windows allow
+	readers int
+	dropped bool
+}
+
+func newSpillFile() (*spillFile, error) {
+	file, err := os.CreateTemp("", "gofs-tar-*")
+	if err != nil {
+		return nil, err
+	}
+	return &spillFile{file: file, removed: os.Remove(file.Name()) == nil}, nil
+}
+
+// open returns a reader of a section of the file, keeping the file open until the reader is closed.
+func (s *spillFile) open(m tarMember, info fs.FileInfo) fs.File {
+	s.mu.Lock()
+	s.readers++
+	s.mu.Unlock()
+
+	var once sync.Once
+	return &sectionFile{SectionReader: io.NewSectionReader(s.file, m.offset, m.size), info: info, close: func() error {
+		once.Do(func() {
+			s.mu.Lock()
+			s.readers--
+			s.mu.Unlock()
+			s.closeIfUnused()
+		})
+		return nil
+	}}
+}
+
+// drop closes the file once no opened member reads from it.
+func (s *spillFile) drop() {
+	s.mu.Lock()
+	s.dropped = true
+	s.mu.Unlock()
+	s.closeIfUnused()
+}
+
+func (s *spillFile) closeIfUnused() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dropped || s.readers > 0 || s.file == nil {
+		return
+	}
+	s.file.Close()
+	if !s.removed {
+		os.Remove(s.file.Name())
+	}
+	s.file = nil
+}
+
+// sectionFile is a regular member of an archive read from a spill file. Being an io.ReaderAt,
+// it can itself be read as a zip archive.
+type sectionFile struct {
+	*io.SectionReader
+	info  fs.FileInfo
+	close func() error
+}
+
+func (f *sectionFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *sectionFile) Close() error               { return f.close() }
+
+// drop releases the contents, returning how many bytes of memory they held.
+func (c *tarContents) drop() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.spill != nil {
+		c.spill.drop()
+	}
+	freed := c.inMemory
+	c.members, c.spill, c.inMemory = nil, nil, 0
+	return freed
+}
+
+func gzipReader(r io.Reader) (io.Reader, error)  { return gzip.NewReader(r) }
+func bzip2Reader(r io.Reader) (io.Reader, error) { return bzip2.NewReader(r), nil }
+
+// readTar returns the function indexing a tar archive compressed by decompress, nil for none.
+func readTar(decompress func(io.Reader) (io.Reader, error)) func(a *archiveFS, name string) (*index, error) {
+	return func(a *archiveFS, name string) (*index, error) {
+		file, info, err := a.openFile(name)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		idx := newIndex(name, info.ModTime())
+		paths := make(map[*member]string) // Path of each regular member as stored in the archive
+		err = walkTar(file, decompress, func(header *tar.Header, _ io.Reader) error {
+			if m := idx.add(header.Name, header.FileInfo()); m != nil && m.children == nil {
+				paths[m] = header.Name
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		contents := &tarContents{}
+		idx.openFile = func(_ string, m *member) (fs.File, error) {
+			return a.openTarMember(name, decompress, contents, paths[m], m.info)
+		}
+		return idx, nil
+	}
+}
+
+// openTarMember opens a regular member of a tar archive, reading the archive's contents if needed.
+func (a *archiveFS) openTarMember(name string, decompress func(io.Reader) (io.Reader, error), contents *tarContents, memberPath string, info fs.FileInfo) (fs.File, error) {
+	contents.mu.Lock()
+	if contents.members == nil {
+		if err := a.loadTar(name, decompress, contents); err != nil {
+			contents.mu.Unlock()
+			return nil, err
+		}
+	}
+	m, ok := contents.members[memberPath]
+	var file fs.File
+	switch {
+	case !ok:
+	case m.spilled:
+		file = contents.spill.open(m, info)
+	default:
+		file = &bytesFile{Reader: bytes.NewReader(m.data), info: info}
+	}
+	contents.mu.Unlock()
+
+	a.touch(contents)
+	if file == nil {
+		return nil, fs.ErrNotExist
+	}
+	return file, nil
+}
+
+// loadTar reads the contents of a tar archive's regular members in a single pass. Members are kept
+// in memory while they are small and the memory budget of the file system allows, and spilled to
+// a temporary file otherwise. contents must be locked.
+func (a *archiveFS) loadTar(name string, decompress func(io.Reader) (io.Reader, error), contents *tarContents) error {
+	file, _, err := a.openFile(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	members := make(map[string]tarMember)
+	var spill *spillFile
+	var inMemory, spilled int64
+	err = walkTar(file, decompress, func(header *tar.Header, r io.Reader) error {
+		if !header.FileInfo().Mode().IsRegular() {
+			return nil
+		}
+		if header.Size <= maxMemberInMemory && a.reserveMemory(header.Size) {
+			inMemory += header.Size
+			data, err := io.ReadAll(r)
+			members[header.Name] = tarMember{data: data, size: int64(len(data))}
+			return err
+		}
+
+		if spill == nil {
+			var err error
+			if spill, err = newSpillFile(); err != nil {
+				return err
+			}
+		}
+		n, err := io.Copy(spill.file, r)
+		members[header.Name] = tarMember{spilled: true, offset: spilled, size: n}
+		spilled += n
+		return err
+	})
+	if err != nil {
+		a.releaseMemory(inMemory)
+		if spill != nil {
+			spill.drop()
+		}
+		return err
+	}
+
+	contents.members, contents.spill, contents.inMemory = members, spill, inMemory
+	return nil
+}
+
+// walkTar calls fn with the header and contents of every member of a tar archive, in order.
+func walkTar(r io.Reader, decompress func(io.Reader) (io.Reader, error), fn func(*tar.Header, io.Reader) error) error {
+	if decompress != nil {
+		var err error
+		if r, err = decompress(r); err != nil {
+			return err
+		}
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(header, tr); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"gofs/internal/fsys"
+	"io"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+// tarArchive builds a tar archive holding the given files.
+func tarArchive(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, data := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func readMember(fileSystem fsys.FS, name string) ([]byte, error) {
+	file, err := fileSystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+func TestTarSpill(t *testing.T) {
+	large := bytes.Repeat([]byte("0123456789abcdef"), (maxMemberInMemory/16)+1)
+	archiveFS := NewFS(fsys.FromFS(fstest.MapFS{
+		"a.tar": {Data: tarArchive(t, map[string][]byte{
+			"small.txt": []byte("small"),
+			"large.bin": large,
+		})},
+	})).(*archiveFS)
+
+	got, err := readMember(archiveFS, "a.tar!/large.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, large) {
+		t.Errorf("large.bin: read %d bytes, want the %d bytes stored", len(got), len(large))
+	}
+	if got, err := readMember(archiveFS, "a.tar!/small.txt"); err != nil || string(got) != "small" {
+		t.Errorf("small.txt = %q, %v, want %q", got, err, "small")
+	}
+
+	// Only the small member is held in memory
+	if archiveFS.inMemory != int64(len("small")) {
+		t.Errorf("%d bytes held in memory, want %d", archiveFS.inMemory, len("small"))
+	}
+
+	// Spilled members are read at random positions, so zip archives inside them can be opened
+	file, err := archiveFS.Open("a.tar!/large.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, ok := file.(io.ReaderAt); !ok {
+		t.Errorf("spilled member %T is not an io.ReaderAt", file)
+	}
+}
+
+func TestTarEviction(t *testing.T) {
+	// More archives than are kept loaded, read concurrently, so contents are dropped and read again
+	const numArchives = maxLoaded * 2
+	files := fstest.MapFS{}
+	for i := 0; i < numArchives; i++ {
+		files[fmt.Sprintf("%d.tar", i)] = &fstest.MapFile{Data: tarArchive(t, map[string][]byte{
+			"member.txt": []byte(fmt.Sprintf("archive %d", i)),
+		})}
+	}
+	archiveFS := NewFS(fsys.FromFS(files)).(*archiveFS)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for round := 0; round < 4; round++ {
+				for i := 0; i < numArchives; i++ {
+					name := fmt.Sprintf("%d.tar!/member.txt", (i+worker)%numArchives)
+					got, err := readMember(archiveFS, name)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					if want := fmt.Sprintf("archive %d", (i+worker)%numArchives); string(got) != want {
+						t.Errorf("%s = %q, want %q", name, got, want)
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(archiveFS.loaded) > maxLoaded {
+		t.Errorf("%d archives loaded, want at most %d", len(archiveFS.loaded), maxLoaded)
+	}
+}
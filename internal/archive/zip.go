@@ -0,0 +1,70 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+)
+
+// readZip indexes a zip archive from its central directory. Members are read by opening the
+// archive again, so no file stays open between reads.
+func readZip(a *archiveFS, name string) (*index, error) {
+	zr, file, info, err := openZip(a, name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	idx := newIndex(name, info.ModTime())
+	positions := make(map[*member]int) // Position of each regular member in the central directory
+	for i, zf := range zr.File {
+		if m := idx.add(zf.Name, zf.FileInfo()); m != nil && m.children == nil {
+			positions[m] = i
+		}
+	}
+
+	idx.openFile = func(_ string, m *member) (fs.File, error) {
+		zr, file, _, err := openZip(a, name)
+		if err != nil {
+			return nil, err
+		}
+		rc, err := zr.File[positions[m]].Open()
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return &memberFile{Reader: rc, info: m.info, close: func() error {
+			rc.Close()
+			return file.Close()
+		}}, nil
+	}
+	return idx, nil
+}
+
+// openZip opens a zip archive. Archives that cannot be read at random positions, like
+// zip files compressed inside a tar archive, are read into memory.
+func openZip(a *archiveFS, name string) (*zip.Reader, fs.File, fs.FileInfo, error) {
+	file, info, err := a.openFile(name)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	readerAt, ok := file.(io.ReaderAt)
+	size := info.Size()
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			file.Close()
+			return nil, nil, nil, err
+		}
+		readerAt, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	zr, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		file.Close()
+		return nil, nil, nil, err
+	}
+	return zr, file, info, nil
+}
@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"fmt"
+	"gofs/internal/traverse"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Color modes of --color
+const (
+	ColorAuto   = "auto"   // Color when printing to a terminal and NO_COLOR is not set
+	ColorAlways = "always" // Always color
+	ColorNever  = "never"  // Never color
+)
+
+// colorReset ends a colored span
+const colorReset = "\033[0m"
+
+// Palette maps file types and extensions to the SGR parameters they are colored with, e.g. "01;34".
+type Palette struct {
+	types      map[string]string // LS_COLORS type keys: di, ln, or, ex, fi, pi, so, bd, cd
+	extensions map[string]string // Lowercased name suffixes, e.g. ".go" or ".tar.gz"
+	linkTarget bool              // ln=target: color links like the file they point to
+}
+
+// defaultPalette is used when LS_COLORS is not set. It keeps gofs' own scheme, loosely following fd's:
+// directories in cyan, then files by the kind of their extension.
+func defaultPalette() *Palette {
+	p := &Palette{
+		types: map[string]string{
+			"di": "36", // Cyan for directories
+			"ln": "35", // Magenta for symbolic links
+			"or": "31", // Red for links to missing files
+			"ex": "31", // Red for executables
+			"fi": "37", // White for other files
+			"pi": "33", // Yellow for named pipes and devices
+			"so": "35",
+			"bd": "33",
+			"cd": "33",
+		},
+		extensions: make(map[string]string),
+	}
+	buckets := []struct {
+		color      string
+		extensions []string
+	}{
+		{"32", []string{".go", ".py", ".cpp", ".c", ".java", ".js", ".ts", ".rs"}}, // Green for code
+		{"34", []string{".json", ".csv", ".xml", ".yaml", ".yml"}},                 // Blue for data
+		{"31", []string{".sh", ".bat", ".ps1"}},                                    // Red for scripts
+		{"33", []string{".md", ".txt", ".log"}},                                    // Yellow for text
+	}
+	for _, bucket := range buckets {
+		for _, ext := range bucket.extensions {
+			p.extensions[ext] = bucket.color
+		}
+	}
+	return p
+}
+
+// ParseLSColors builds a palette from an LS_COLORS value like "di=01;34:ln=01;36:*.go=32".
+// Types it leaves out get the colors GNU ls gives them. Entries gofs has no use for are ignored.
+func ParseLSColors(value string) *Palette {
+	p := &Palette{
+		types: map[string]string{
+			"di": "01;34",
+			"ln": "01;36",
+			"ex": "01;32",
+			"pi": "40;33",
+			"so": "01;35",
+			"bd": "40;33;01",
+			"cd": "40;33;01",
+		},
+		extensions: make(map[string]string),
+	}
+
+	for _, entry := range strings.Split(value, ":") {
+		key, color, ok := strings.Cut(entry, "=")
+		if !ok || key == "" {
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(key, "*"); ok {
+			p.extensions[strings.ToLower(suffix)] = color
+			continue
+		}
+		if key == "ln" && color == "target" {
+			p.linkTarget = true
+			continue
+		}
+		p.types[key] = color
+	}
+	return p
+}
+
+// ResolvePalette returns the palette for a --color mode, or nil when results are not colored.
+func ResolvePalette(mode string) (*Palette, error) {
+	switch mode {
+	case ColorNever:
+		return nil, nil
+	case ColorAuto, "":
+		if os.Getenv("NO_COLOR") != "" || !isTerminal(os.Stdout) {
+			return nil, nil
+		}
+	case ColorAlways:
+	default:
+		return nil, fmt.Errorf("invalid color mode: %q, expected auto, always or never", mode)
+	}
+
+	if lsColors, ok := os.LookupEnv("LS_COLORS"); ok && lsColors != "" {
+		return ParseLSColors(lsColors), nil
+	}
+	return defaultPalette(), nil
+}
+
+// isTerminal reports whether a file is a terminal rather than a pipe or a regular file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// directoryColor returns the color of the directories leading up to a result.
+func (p *Palette) directoryColor() string {
+	return p.types["di"]
+}
+
+// entryColor returns the color of a result, chosen by its file type like ls does:
+// the type itself, then executables, then the longest matching extension, then regular files.
+func (p *Palette) entryColor(entry traverse.Entry) string {
+	if entry.DirEntry == nil {
+		return ""
+	}
+
+	mode := entry.DirEntry.Type()
+	if mode&fs.ModeSymlink != 0 {
+		target, err := entry.FS.Stat(entry.Path)
+		switch {
+		case err != nil:
+			if color, ok := p.types["or"]; ok {
+				return color
+			}
+			return p.types["ln"]
+		case p.linkTarget:
+			// Like ls, the extension is the target's, not the link's
+			name := entry.Path
+			if targetPath, err := entry.FS.ReadLink(entry.Path); err == nil {
+				name = targetPath
+			}
+			return p.colorForMode(name, target.Mode(), func() (fs.FileInfo, error) { return target, nil })
+		}
+		return p.types["ln"]
+	}
+	return p.colorForMode(entry.Path, mode, entry.Info)
+}
+
+// colorForMode picks a color for a file of the given type. info is only called for regular files,
+// to check whether they are executable.
+func (p *Palette) colorForMode(path string, mode fs.FileMode, info func() (fs.FileInfo, error)) string {
+	switch {
+	case mode.IsDir():
+		return p.types["di"]
+	case mode&fs.ModeNamedPipe != 0:
+		return p.types["pi"]
+	case mode&fs.ModeSocket != 0:
+		return p.types["so"]
+	case mode&fs.ModeCharDevice != 0:
+		return p.types["cd"]
+	case mode&fs.ModeDevice != 0:
+		return p.types["bd"]
+	}
+
+	if color, ok := p.types["ex"]; ok {
+		if fileInfo, err := info(); err == nil && fileInfo.Mode().Perm()&0o111 != 0 {
+			return color
+		}
+	}
+	if color, ok := p.extensionColor(path); ok {
+		return color
+	}
+	return p.types["fi"]
+}
+
+// extensionColor returns the color of the longest LS_COLORS suffix the name of a path ends with.
+func (p *Palette) extensionColor(path string) (string, bool) {
+	name := strings.ToLower(filepath.Base(path))
+	for i := range name {
+		if color, ok := p.extensions[name[i:]]; ok {
+			return color, true
+		}
+	}
+	return "", false
+}
+
+// paint wraps text in the given SGR color, leaving it alone when there is none.
+func paint(text, color string) string {
+	if color == "" || color == "0" || color == "00" {
+		return text
+	}
+	return "\033[" + color + "m" + text + colorReset
+}
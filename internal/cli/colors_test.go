@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"gofs/internal/fsys"
+	"gofs/internal/traverse"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLSColors(t *testing.T) {
+	p := ParseLSColors("di=01;34:ln=01;36:or=40;31;01:ex=01;32:fi=0:*.go=32:*.GZ=31:*.tar.gz=01;31:*README=33:no-equals:=1:mi=05")
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"di", "01;34"},
+		{"ln", "01;36"},
+		{"or", "40;31;01"},
+		{"ex", "01;32"},
+		{"fi", "0"},
+		{"so", "01;35"}, // Left out, so GNU ls' default
+		{"mi", "05"},    // Kept even though unused
+	}
+	for _, tt := range tests {
+		if got := p.types[tt.key]; got != tt.want {
+			t.Errorf("types[%q] = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	extensions := []struct {
+		path string
+		want string
+	}{
+		{"main.go", "32"},
+		{"MAIN.GO", "32"},     // Suffixes match case-insensitively
+		{"a.gz", "31"},        // *.GZ lowercased
+		{"a.tar.gz", "01;31"}, // The longest suffix wins
+		{"dir/README", "33"},  // Suffixes need not be extensions
+		{"xREADME", "33"},     // Any name ending with the suffix
+		{"main.go.txt", ""},   // Only the end of the name counts
+		{"go/main.txt", ""},   // Only the name counts
+	}
+	for _, tt := range extensions {
+		if got, _ := p.extensionColor(tt.path); got != tt.want {
+			t.Errorf("extensionColor(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	if p.linkTarget {
+		t.Error("ln=01;36 colors links as targets")
+	}
+	if !ParseLSColors("ln=target").linkTarget {
+		t.Error("ln=target does not color links as targets")
+	}
+}
+
+func TestEntryColor(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, mode os.FileMode) {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(filepath.Join(dir, name), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", 0o644)
+	write("notes", 0o644)
+	write("run.go", 0o755)
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("main.go", filepath.Join(dir, "link")); err != nil {
+		t.Skipf("cannot create symbolic links: %v", err)
+	}
+	if err := os.Symlink("missing", filepath.Join(dir, "broken")); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	colorOf := func(p *Palette, name string) string {
+		for _, d := range entries {
+			if d.Name() == name {
+				return p.entryColor(traverse.NewEntry(fsys.OS, filepath.Join(dir, name), dir, d))
+			}
+		}
+		t.Fatalf("%s was not read", name)
+		return ""
+	}
+
+	tests := []struct {
+		lsColors string
+		name     string
+		want     string
+	}{
+		{"di=01;34", "sub", "01;34"},
+		{"ex=01;32:*.go=32", "run.go", "01;32"}, // Executables before extensions
+		{"ex=01;32:*.go=32", "main.go", "32"},
+		{"fi=37", "notes", "37"},
+		{"ln=01;36:*.go=32", "link", "01;36"},
+		{"ln=target:*.go=32", "link", "32"}, // Colored like main.go
+		{"ln=01;36:or=31", "broken", "31"},
+		{"ln=01;36", "broken", "01;36"}, // Without or, broken links are colored as links
+	}
+	for _, tt := range tests {
+		if got := colorOf(ParseLSColors(tt.lsColors), tt.name); got != tt.want {
+			t.Errorf("LS_COLORS=%q: %s colored %q, want %q", tt.lsColors, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"context"
+	"gofs/internal/fsys"
+	"gofs/internal/traverse"
+	"gofs/pkg/gofs"
+	"io/fs"
+)
+
+// Entries turns the results of the library back into traversal entries for the output stages.
+func Entries(ctx context.Context, results <-chan gofs.Entry) <-chan traverse.Entry {
+	entries := make(chan traverse.Entry, cap(results))
+	go func() {
+		defer close(entries)
+		for result := range results {
+			select {
+			case entries <- traversalEntry(result):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return entries
+}
+
+// traversalEntry converts a library result, keeping the file information it has already loaded.
+func traversalEntry(result gofs.Entry) traverse.Entry {
+	fileSystem, ok := result.FS.(fsys.FS)
+	if !ok {
+		fileSystem = fsys.FromFS(result.FS)
+	}
+	entry := traverse.NewEntry(fileSystem, result.Path, result.Root, loadedDirEntry{result.DirEntry, result.Info})
+	for _, match := range result.Matches {
+		entry.Matches = append(entry.Matches, traverse.LineMatch(match))
+	}
+	return entry
+}
+
+// loadedDirEntry answers Info with the result's cached file information instead of reading it again.
+type loadedDirEntry struct {
+	fs.DirEntry
+	info func() (fs.FileInfo, error)
+}
+
+func (d loadedDirEntry) Info() (fs.FileInfo, error) { return d.info() }
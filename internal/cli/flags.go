@@ -1,24 +1,33 @@
 package cli
 
 import (
-	"errors"
+	"gofs/internal/output"
+	"gofs/pkg/gofs"
 	"runtime"
 
 	"github.com/spf13/cobra"
 )
 
 type Config struct {
-	Root          string
+	Roots         []string // Directories the traversal starts from, taken from [pathname...]
 	Pattern       string
-	Pathname      string
 	Depth         int
 	MaxThreads    int
 	CaseSensitive bool
+	IgnoreCase    bool
 	IncludeHidden bool
-	IncludeIgnore bool
+	Archives      bool               // Descend into zip and tar archives
+	Ignore        gofs.IgnoreOptions // Which ignore files are respected
 	GlobPattern   string
-	FilterOptions map[string]interface{} // Holds filter-related options
-	FormatOptions map[string]interface{} // Holds format-related options
+	FullPath      bool
+	Sort          bool
+	Contains      string             // Regex file contents must match, empty when not searching contents
+	ShowLines     bool               // Print the lines matching Contains
+	Context       int                // Lines of context around each content match
+	MaxFileSize   string             // Size cap for content search, e.g. "10M"
+	Filters       gofs.FilterOptions // Which filters results have to pass
+	Formats       output.Options     // How results are displayed
+	Print         PrintOptions       // How lines are written
 }
 
 // DefineFlags adds flags to the root command
@@ -29,89 +38,189 @@ func DefineFlags(cmd *cobra.Command) {
 
 	// Search flag
 	cmd.Flags().StringP("glob", "g", "", "Search using a glob pattern (default: empty string)")
+	cmd.Flags().BoolP("full-path", "p", false, "Match the pattern against the full path instead of the base name")
 
 	// Traverse flags
 	cmd.Flags().IntP("max-depth", "d", -1, "Limit search to a specific directory depth (-1 for no limit)")
 	cmd.Flags().IntP("max-threads", "T", runtime.NumCPU(), "Set the maximum number of parallel threads for traversal")
-	cmd.Flags().BoolP("case-sensitive", "S", false, "Perform case-sensitive searches")
+	cmd.Flags().BoolP("case-sensitive", "S", false, "Perform case-sensitive searches (default: smart case)")
+	cmd.Flags().BoolP("ignore-case", "i", false, "Perform case-insensitive searches (default: smart case)")
 	cmd.Flags().BoolP("hidden", "H", false, "Include hidden files in the search")
-	cmd.Flags().BoolP("ignore", "I", false, "Include .*ignore files like .gitignore")
+	cmd.Flags().BoolP("no-ignore", "I", false, "Do not respect .gitignore, .ignore and git's exclude files")
+	cmd.Flags().Bool("no-ignore-vcs", false, "Do not respect .gitignore, .git/info/exclude and core.excludesFile")
+	cmd.Flags().StringArray("ignore-file", nil, "Add a custom ignore file, matched relative to each search root (can be repeated)")
+	cmd.Flags().Bool("search-archives", false, "Search inside .zip, .tar, .tar.gz, .tgz and .tar.bz2 archives, showing members as archive!/path")
+
+	// Content search flags
+	cmd.Flags().String("contains", "", "Only show files whose contents match a regex")
+	cmd.Flags().Bool("show-lines", false, "Print the lines matching --contains with their line numbers")
+	cmd.Flags().IntP("context", "C", 0, "Print this many lines of context around each match (implies --show-lines)")
+	cmd.Flags().String("max-filesize", "", "Skip files larger than this size when searching contents (e.g. 10M, 512Ki)")
 
 	// Filter flags
-	cmd.Flags().StringP("extension", "e", "", "Filter results by file extensions")
-	cmd.Flags().StringP("file-type", "t", "", "Filter results by file type (file, dir, symlink)")
-	cmd.Flags().StringP("exclude", "x", "", "Exclude files/directories matching a glob pattern")
+	cmd.Flags().StringArrayP("extension", "e", nil, "Filter results by file extensions, case-insensitive, e.g. go or tar.gz (can be repeated or comma-separated)")
+	cmd.Flags().StringArrayP("file-type", "t", nil, "Filter results by file type: file, dir, symlink, socket, pipe, char-device, block-device, executable, empty or broken-symlink (can be repeated to match any)")
+	cmd.Flags().StringArrayP("exclude", "x", nil, "Exclude files/directories matching a glob pattern; patterns with a / match the path relative to the search root (can be repeated or comma-separated)")
+	cmd.Flags().StringArray("size", nil, "Filter files by size: +10M (at least), -4k (at most), 1Mi (exactly) or 10k..2M (can be repeated)")
+	cmd.Flags().String("changed-within", "", "Filter results changed within a duration (e.g. 2d, 10h30m, 1w) or since a date (e.g. 2024-01-01)")
+	cmd.Flags().String("changed-before", "", "Filter results changed before a date (e.g. 2024-01-01) or longer ago than a duration (e.g. 2d)")
+	cmd.Flags().String("newer", "", "Filter results changed after the given reference file")
+	cmd.Flags().String("older", "", "Filter results changed before the given reference file")
+	cmd.Flags().String("owner", "", "Filter results by owner: user, user:group or :group, as names or IDs; prefix either with ! to exclude")
+	cmd.Flags().String("perm", "", "Filter results by permissions: exactly 0644, all of -u+x or any of /o+w")
+	cmd.Flags().String("where", "", "Filter results with an expression, e.g. 'ext:go and (size>100k or mtime<7d) and not path:vendor/**'")
+	cmd.Flags().String("time-field", "", "Timestamp the time filters compare: mtime (default), atime, ctime or btime")
 
 	// Format flags
+	cmd.Flags().String("format", "", "Print results as json (an array) or ndjson (one object per line) with their metadata and matched lines")
 	cmd.Flags().BoolP("absolute-path", "A", false, "Display resuults as absolute paths")
 	cmd.Flags().BoolP("long-list", "l", false, "Display results in long list format")
-	cmd.Flags().BoolP("hyper-link", "L", false, "Display results as hyperlinks")
+	cmd.Flags().StringP("hyper-link", "L", HyperlinkAuto, "When to print results as terminal hyperlinks: auto (with --color=auto in terminals known to support them), always or never; -L alone means always")
+	cmd.Flags().Lookup("hyper-link").NoOptDefVal = HyperlinkAlways
+	cmd.Flags().String("template", "", "Print every result with a template like '{path}\\t{size:h}\\t{mtime:%Y-%m-%d}'; placeholders are the --format json keys plus name, stem, ext, parent and relpath")
+	cmd.Flags().String("go-template", "", "Print every result with a Go text/template, e.g. '{{.Name}} {{human .Size}}'")
+	cmd.Flags().String("quote", "", "Quote names with control characters or spaces: shell, c or literal (default literal)")
+
+	// Output flags
+	cmd.Flags().Bool("sort", false, "Sort results after the search completes instead of printing them as they are found")
+	cmd.Flags().String("color", ColorAuto, "When to color results: auto (when printing to a terminal and NO_COLOR is not set), always or never; colors follow LS_COLORS")
+	cmd.Flags().BoolP("print0", "0", false, "End every result with a NUL instead of a newline and print names as they are, for xargs -0")
 }
 
 // ParseFlags parses the flags and returns a Config struct
 func ParseFlags(cmd *cobra.Command, args []string) Config {
 	var pattern string
 	globPattern, _ := cmd.Flags().GetString("glob")
+	fullPath, _ := cmd.Flags().GetBool("full-path")
 
-	if len(args) > 0 {
+	pathArgs := args
+	if globPattern != "" {
+		pattern = globPattern // The glob pattern replaces the positional pattern argument
+	} else if len(args) > 0 {
 		pattern = args[0]
-	} else if globPattern != "" {
-		pattern = globPattern // Use the glob pattern if no positional pattern argument is provided
+		pathArgs = args[1:]
 	} else {
 		pattern = "." // If neither pattern nor glob is specified
 	}
 
-	pathname := "."
-	if len(args) > 1 {
-		pathname = args[1]
+	roots := []string{"."}
+	if len(pathArgs) > 0 {
+		roots = pathArgs
 	}
 
 	depth, _ := cmd.Flags().GetInt("max-depth")
 	maxThreads, _ := cmd.Flags().GetInt("max-threads")
 	caseSensitive, _ := cmd.Flags().GetBool("case-sensitive")
+	ignoreCase, _ := cmd.Flags().GetBool("ignore-case")
 	includeHidden, _ := cmd.Flags().GetBool("hidden")
-	includeIgnore, _ := cmd.Flags().GetBool("ignore")
-	extension, _ := cmd.Flags().GetString("extension")
-	fileType, _ := cmd.Flags().GetString("file-type")
-	exclude, _ := cmd.Flags().GetString("exclude")
+	searchArchives, _ := cmd.Flags().GetBool("search-archives")
+	noIgnore, _ := cmd.Flags().GetBool("no-ignore")
+	noIgnoreVCS, _ := cmd.Flags().GetBool("no-ignore-vcs")
+	ignoreFiles, _ := cmd.Flags().GetStringArray("ignore-file")
+	contains, _ := cmd.Flags().GetString("contains")
+	showLines, _ := cmd.Flags().GetBool("show-lines")
+	contextLines, _ := cmd.Flags().GetInt("context")
+	maxFileSize, _ := cmd.Flags().GetString("max-filesize")
+	extensions, _ := cmd.Flags().GetStringArray("extension")
+	fileTypes, _ := cmd.Flags().GetStringArray("file-type")
+	excludes, _ := cmd.Flags().GetStringArray("exclude")
+	sizes, _ := cmd.Flags().GetStringArray("size")
+	changedWithin, _ := cmd.Flags().GetString("changed-within")
+	changedBefore, _ := cmd.Flags().GetString("changed-before")
+	newer, _ := cmd.Flags().GetString("newer")
+	older, _ := cmd.Flags().GetString("older")
+	timeField, _ := cmd.Flags().GetString("time-field")
+	owner, _ := cmd.Flags().GetString("owner")
+	perm, _ := cmd.Flags().GetString("perm")
+	where, _ := cmd.Flags().GetString("where")
+	format, _ := cmd.Flags().GetString("format")
 	absolutePath, _ := cmd.Flags().GetBool("absolute-path")
 	longList, _ := cmd.Flags().GetBool("long-list")
-	hyperlink, _ := cmd.Flags().GetBool("hyper-link")
-
-	// Construct FilterOptions as a map
-	filterOptions := map[string]interface{}{
-		"Extension": extension,
-		"FileType":  fileType,
-		"Exclude":   exclude,
-		"AbsPath":   absolutePath,
-	}
-
-	formatOptions := map[string]interface{}{
-		"AbsolutePath": absolutePath,
-		"LongList":     longList,
-		"Hyperlink":    hyperlink,
-	}
+	hyperlink, _ := cmd.Flags().GetString("hyper-link")
+	quote, _ := cmd.Flags().GetString("quote")
+	template, _ := cmd.Flags().GetString("template")
+	goTemplate, _ := cmd.Flags().GetString("go-template")
+	print0, _ := cmd.Flags().GetBool("print0")
+	color, _ := cmd.Flags().GetString("color")
+	sortResults, _ := cmd.Flags().GetBool("sort")
 
 	return Config{
-		Root:          ".",
+		Roots:         roots,
 		Pattern:       pattern,
-		Pathname:      pathname,
 		Depth:         depth,
 		MaxThreads:    maxThreads,
 		CaseSensitive: caseSensitive,
+		IgnoreCase:    ignoreCase,
 		IncludeHidden: includeHidden,
-		IncludeIgnore: includeIgnore,
-		GlobPattern:   globPattern,
-		FilterOptions: filterOptions,
-		FormatOptions: formatOptions,
+		Archives:      searchArchives,
+		Ignore: gofs.IgnoreOptions{
+			NoIgnore:    noIgnore,
+			NoIgnoreVCS: noIgnoreVCS,
+			Files:       ignoreFiles,
+		},
+		GlobPattern: globPattern,
+		FullPath:    fullPath,
+		Sort:        sortResults,
+		Contains:    contains,
+		ShowLines:   showLines || contextLines > 0,
+		Context:     contextLines,
+		MaxFileSize: maxFileSize,
+		Filters: gofs.FilterOptions{
+			Extensions:    splitList(extensions),
+			FileTypes:     fileTypes,
+			Excludes:      splitList(excludes),
+			Sizes:         sizes,
+			ChangedWithin: changedWithin,
+			ChangedBefore: changedBefore,
+			Newer:         newer,
+			Older:         older,
+			TimeField:     timeField,
+			Owner:         owner,
+			Perm:          perm,
+			Where:         where,
+		},
+		Formats: output.Options{
+			Format:       format,
+			AbsolutePath: absolutePath,
+			LongList:     longList,
+			Quote:        quote,
+			Template:     template,
+			GoTemplate:   goTemplate,
+		},
+		Print: PrintOptions{
+			NullSeparated: print0,
+			Color:         color,
+			Hyperlink:     hyperlink,
+		},
 	}
 }
 
-// ValidateFlags ensures valid flag combinations
-func ValidateFlags(cmd *cobra.Command, args []string) error {
-	if len(args) > 2 {
-		return errors.New("too many arguments; expected <pattern> and [pathname]")
+// splitList splits comma-separated flag values. Commas inside braces belong to a glob
+// alternative like "*.{js,ts}" and do not split.
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		depth, start := 0, 0
+		for i, c := range value {
+			switch {
+			case c == '{':
+				depth++
+			case c == '}' && depth > 0:
+				depth--
+			case c == ',' && depth == 0:
+				items = append(items, value[start:i])
+				start = i + 1
+			}
+		}
+		items = append(items, value[start:])
 	}
 
-	return nil
+	// Drop empty items left by stray commas
+	kept := items[:0]
+	for _, item := range items {
+		if item != "" {
+			kept = append(kept, item)
+		}
+	}
+	return kept
 }
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Hyperlink modes of --hyper-link
+const (
+	HyperlinkAuto   = "auto"   // Link with --color=auto, when printing to a terminal known to support links
+	HyperlinkAlways = "always" // Always link, what -L alone means
+	HyperlinkNever  = "never"  // Never link
+)
+
+// hyperlinkEnd closes an OSC 8 hyperlink
+const hyperlinkEnd = "\033]8;;\033\\"
+
+// hyperlinkStart opens an OSC 8 hyperlink to a URL; the text printed until hyperlinkEnd is clickable.
+func hyperlinkStart(url string) string {
+	return "\033]8;;" + url + "\033\\"
+}
+
+// ResolveHyperlink reports whether results are printed as hyperlinks. In auto mode they are only
+// when colors are decided automatically too and the terminal is one of those known to show links;
+// others may print the escape sequences as garbage.
+func ResolveHyperlink(opts PrintOptions) (bool, error) {
+	switch opts.Hyperlink {
+	case HyperlinkNever:
+		return false, nil
+	case HyperlinkAlways:
+	case HyperlinkAuto, "":
+		if opts.Color != ColorAuto && opts.Color != "" {
+			return false, nil
+		}
+		if !isTerminal(os.Stdout) || !terminalSupportsHyperlinks() {
+			return false, nil
+		}
+	default:
+		return false, fmt.Errorf("invalid hyperlink mode: %q, expected auto, always or never", opts.Hyperlink)
+	}
+
+	// Escape sequences would end up in the names read by xargs -0
+	return !opts.NullSeparated, nil
+}
+
+// terminalSupportsHyperlinks recognizes terminals with OSC 8 support from the environment they set.
+func terminalSupportsHyperlinks() bool {
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty":
+		return true
+	}
+	if os.Getenv("WT_SESSION") != "" || os.Getenv("KITTY_WINDOW_ID") != "" || os.Getenv("DOMTERM") != "" {
+		return true
+	}
+
+	// GNOME Terminal, Tilix and other VTE based terminals since VTE 0.50
+	if version, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && version >= 5000 {
+		return true
+	}
+
+	term := os.Getenv("TERM")
+	return term == "xterm-kitty" || term == "alacritty" || strings.HasPrefix(term, "foot")
+}
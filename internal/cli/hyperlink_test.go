@@ -0,0 +1,32 @@
+package cli
+
+import "testing"
+
+func TestResolveHyperlink(t *testing.T) {
+	tests := []struct {
+		opts    PrintOptions
+		want    bool
+		wantErr bool
+	}{
+		{PrintOptions{Hyperlink: HyperlinkAlways}, true, false},
+		{PrintOptions{Hyperlink: HyperlinkAlways, Color: ColorNever}, true, false},
+		{PrintOptions{Hyperlink: HyperlinkAlways, NullSeparated: true}, false, false},
+		{PrintOptions{Hyperlink: HyperlinkNever}, false, false},
+		{PrintOptions{Hyperlink: HyperlinkAuto, Color: ColorAlways}, false, false},
+		{PrintOptions{Hyperlink: "sometimes"}, false, true},
+	}
+	for _, tt := range tests {
+		got, err := ResolveHyperlink(tt.opts)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ResolveHyperlink(%+v) = %v, %v, want %v, error %v", tt.opts, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestHyperlinkStart(t *testing.T) {
+	got := hyperlinkStart("file://host/tmp/a%20b") + "a b" + hyperlinkEnd
+	want := "\x1b]8;;file://host/tmp/a%20b\x1b\\a b\x1b]8;;\x1b\\"
+	if got != want {
+		t.Errorf("hyperlink = %q, want %q", got, want)
+	}
+}
@@ -1,74 +1,101 @@
 package cli
 
 import (
-	"fmt"
+	"bufio"
+	"gofs/internal/output"
+	"os"
 	"path/filepath"
 	"strings"
 )
 
-// ANSI color codes following fd's color scheme
-const (
-	colorReset   = "\033[0m"  // Reset color
-	colorCode    = "\033[32m" // Green for directories
-	colorData    = "\033[34m" // Blue for data files
-	colorDir     = "\033[36m" // Cyan for code files
-	colorText    = "\033[33m" // Yellow for text files
-	colorDefault = "\033[37m" // White for other files
-	colorHidden  = "\033[90m" // Grey for hidden files
-	colorExec    = "\033[31m" // Red for executables and scripts
-)
+// PrintOptions controls how lines are written to standard output.
+type PrintOptions struct {
+	NullSeparated bool   // End every line with a NUL instead of a newline, without colors
+	Color         string // --color: auto, always or never
+	Hyperlink     string // --hyper-link: auto, always or never
+}
 
-// getColorForFileType determines the color based on file extension
-func getColorForFileType(file string) string {
-	ext := strings.ToLower(filepath.Ext(file))
+// Printer writes formatted lines to standard output.
+type Printer struct {
+	opts    PrintOptions
+	palette *Palette // Nil when lines are not colored
+}
 
-	switch ext {
-	case ".go", ".py", ".cpp", ".c", ".java", ".js", ".ts", ".rs":
-		return colorCode // Code files
-	case ".json", ".csv", ".xml", ".yaml", ".yml":
-		return colorData // Data files
-	case ".sh", ".bat", ".ps1":
-		return colorExec // Executables and scripts
-	case ".md", ".txt", ".log":
-		return colorText // Text files
-	default:
-		return colorDefault // Default color for other files
+// NewPrinter validates the options and decides whether lines are colored.
+func NewPrinter(opts PrintOptions) (*Printer, error) {
+	if opts.NullSeparated {
+		opts.Color = ColorNever // Colors would end up in the names read by xargs -0
 	}
+	palette, err := ResolvePalette(opts.Color)
+	if err != nil {
+		return nil, err
+	}
+	return &Printer{opts: opts, palette: palette}, nil
 }
 
-// PrintResults automatically handles long and short formats
-func PrintResults(results []string) {
-	for _, result := range results {
-		// Check if the result contains a timestamp pattern to identify long list output
-		if strings.Contains(result, "IST") {
-			// Split metadata and pathname
-			infoEndIndex := strings.LastIndex(result, "IST") + len("IST")
-			info := result[:infoEndIndex]
-			pathname := result[infoEndIndex+1:]
+// PrintResults prints the formatted lines as they arrive, coloring only the path of each line
+func (p *Printer) PrintResults(results <-chan output.Line) {
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	for line := range results {
+		p.printResult(out, line)
+
+		// Results are shown as they are found, unless more are already waiting
+		if len(results) == 0 {
+			out.Flush()
+		}
+	}
+}
 
-			// Print the uncolored metadata and colored path
-			fmt.Print(info + " ")
-			printColoredPathname(pathname)
+// printResult prints a single line: uncolored metadata, the path and any trailing text
+func (p *Printer) printResult(out *bufio.Writer, line output.Line) {
+	if line.Info != "" {
+		out.WriteString(line.Info + " ")
+	}
+	if line.Path != "" {
+		if line.Link != "" {
+			out.WriteString(hyperlinkStart(line.Link))
+		}
+		if p.palette == nil {
+			out.WriteString(line.Path)
 		} else {
-			// Treat it as a short list
-			printColoredPathname(result)
+			p.printColoredPathname(out, line)
+		}
+		if line.Link != "" {
+			out.WriteString(hyperlinkEnd)
 		}
-		fmt.Println()
+	}
+	out.WriteString(line.Text)
+	if p.opts.NullSeparated {
+		out.WriteByte(0)
+	} else {
+		out.WriteByte('\n')
 	}
 }
 
-// printColoredPathname applies color only to the pathname components
-func printColoredPathname(pathname string) {
-	parts := strings.Split(pathname, string(filepath.Separator))
-	for i, part := range parts {
+// printColoredPathname colors the directories leading up to a result, then the result by its file type
+func (p *Printer) printColoredPathname(out *bufio.Writer, line output.Line) {
+	separator := string(filepath.Separator)
+	dir, name := "", strings.TrimSuffix(line.Path, separator)
+	if i := strings.LastIndex(name, separator); i >= 0 {
+		dir, name = name[:i+1], name[i+1:]
+	}
+
+	// Color each directory on its own, keeping the separators uncolored
+	for _, part := range strings.SplitAfter(dir, separator) {
 		if part == "" {
-			continue // Ignore empty parts for better formatting
+			continue
 		}
-
-		if i == len(parts)-1 { // Last part (the file itself)
-			fmt.Printf("%s%s%s", getColorForFileType(part), part, colorReset)
-		} else { // Intermediate directories
-			fmt.Printf("%s%s%s%s", colorDir, part, colorReset, string(filepath.Separator))
+		if part == separator {
+			out.WriteString(separator) // Leading separator of absolute paths
+			continue
 		}
+		out.WriteString(paint(strings.TrimSuffix(part, separator), p.palette.directoryColor()) + separator)
+	}
+
+	out.WriteString(paint(name, p.palette.entryColor(line.Entry)))
+	if strings.HasSuffix(line.Path, separator) && name != "" {
+		out.WriteString(separator) // Directories keep their trailing separator uncolored
 	}
 }
@@ -0,0 +1,178 @@
+package filter
+
+import (
+	"fmt"
+	"gofs/internal/filter/expr"
+	"gofs/internal/filter/filters"
+	"gofs/internal/fsys"
+	"gofs/internal/traverse"
+	"gofs/utils"
+	"time"
+)
+
+// The built-in filters, cheapest first
+func init() {
+	Register("exclude", 10, buildExclude)
+	Register("extension", 20, buildExtension)
+	Register("file-type", 30, buildFileType)
+	Register("size", 40, buildSize)
+	Register("time", 50, buildTime)
+	Register("owner", 60, buildOwner)
+	Register("perm", 70, buildPerm)
+	Register("where", 80, buildWhere)
+}
+
+func buildExclude(opts Options) (Filter, bool, error) {
+	if len(opts.Excludes) == 0 {
+		return nil, false, nil
+	}
+	var patterns []filters.ExcludePattern
+	for _, exclude := range opts.Excludes {
+		pattern, err := filters.CompileExcludePattern(exclude)
+		if err != nil {
+			return nil, false, fmt.Errorf("error applying exclude filter: %v", err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return Func(func(entry traverse.Entry) bool {
+		return filters.ExcludeFilter(entry.Path, entry.Root, patterns)
+	}), true, nil
+}
+
+func buildExtension(opts Options) (Filter, bool, error) {
+	if len(opts.Extensions) == 0 {
+		return nil, false, nil
+	}
+	return Func(func(entry traverse.Entry) bool {
+		return filters.ExtensionFilter(entry.Path, opts.Extensions)
+	}), true, nil
+}
+
+func buildFileType(opts Options) (Filter, bool, error) {
+	if len(opts.FileTypes) == 0 {
+		return nil, false, nil
+	}
+	for _, fileType := range opts.FileTypes {
+		if err := utils.ValidateFileType(fileType); err != nil {
+			return nil, false, fmt.Errorf("error applying file type filter: %v", err)
+		}
+	}
+	return Func(func(entry traverse.Entry) bool {
+		return filters.FileTypeFilter(entry.FS, entry.Path, opts.FileTypes)
+	}), true, nil
+}
+
+func buildSize(opts Options) (Filter, bool, error) {
+	if len(opts.Sizes) == 0 {
+		return nil, false, nil
+	}
+	var constraints []filters.SizeConstraint
+	for _, size := range opts.Sizes {
+		constraint, err := filters.ParseSizeConstraint(size)
+		if err != nil {
+			return nil, false, fmt.Errorf("error applying size filter: %v", err)
+		}
+		constraints = append(constraints, constraint)
+	}
+	return Func(func(entry traverse.Entry) bool {
+		return filters.SizeFilter(entry.FS, entry.Path, constraints)
+	}), true, nil
+}
+
+// buildTime combines --changed-within, --changed-before, --newer and --older into one filter.
+// They all compare the same timestamp, the modification time unless --time-field says otherwise.
+func buildTime(opts Options) (Filter, bool, error) {
+	timeField := opts.TimeField
+	if timeField == "" {
+		timeField = "mtime"
+	}
+	if err := utils.ValidateTimeField(timeField); err != nil {
+		return nil, false, fmt.Errorf("error applying time filter: %v", err)
+	}
+
+	var constraints []filters.TimeConstraint
+
+	// --changed-within and --changed-before take a duration or a date
+	for _, bound := range []struct {
+		value string
+		after bool
+	}{{opts.ChangedWithin, true}, {opts.ChangedBefore, false}} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := utils.ParsePointInTime(bound.value, opts.Now)
+		if err != nil {
+			return nil, false, fmt.Errorf("error applying time filter: %v", err)
+		}
+		constraints = append(constraints, timeConstraint(t, bound.after))
+	}
+
+	// --newer and --older take a reference file on the operating system whose own timestamp is the bound
+	for _, bound := range []struct {
+		value string
+		after bool
+	}{{opts.Newer, true}, {opts.Older, false}} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := filters.FileTime(fsys.OS, bound.value, timeField)
+		if err != nil {
+			return nil, false, fmt.Errorf("error applying time filter: cannot read the reference file: %v", err)
+		}
+		constraints = append(constraints, timeConstraint(t, bound.after))
+	}
+
+	if len(constraints) == 0 {
+		return nil, false, nil
+	}
+	return Func(func(entry traverse.Entry) bool {
+		return filters.TimeFilter(entry.FS, entry.Path, timeField, constraints)
+	}), true, nil
+}
+
+// timeConstraint bounds a timestamp from below when after is set, otherwise from above.
+func timeConstraint(t time.Time, after bool) filters.TimeConstraint {
+	if after {
+		return filters.TimeConstraint{After: t}
+	}
+	return filters.TimeConstraint{Before: t}
+}
+
+func buildOwner(opts Options) (Filter, bool, error) {
+	if opts.Owner == "" {
+		return nil, false, nil
+	}
+	constraint, err := filters.ParseOwnerConstraint(opts.Owner)
+	if err != nil {
+		return nil, false, fmt.Errorf("error applying owner filter: %v", err)
+	}
+	return Func(func(entry traverse.Entry) bool {
+		return filters.OwnerFilter(entry.FS, entry.Path, constraint)
+	}), true, nil
+}
+
+func buildPerm(opts Options) (Filter, bool, error) {
+	if opts.Perm == "" {
+		return nil, false, nil
+	}
+	constraint, err := filters.ParsePermConstraint(opts.Perm)
+	if err != nil {
+		return nil, false, fmt.Errorf("error applying permission filter: %v", err)
+	}
+	return Func(func(entry traverse.Entry) bool {
+		return filters.PermFilter(entry.FS, entry.Path, constraint)
+	}), true, nil
+}
+
+func buildWhere(opts Options) (Filter, bool, error) {
+	if opts.Where == "" {
+		return nil, false, nil
+	}
+	expression, err := expr.Parse(opts.Where, opts.Now)
+	if err != nil {
+		return nil, false, fmt.Errorf("error applying --where filter: %v", err)
+	}
+	return Func(func(entry traverse.Entry) bool {
+		return expression.Eval(expr.NewMetadata(entry))
+	}), true, nil
+}
@@ -0,0 +1,183 @@
+package expr
+
+import (
+	"gofs/internal/filter/filters"
+	"gofs/internal/glob"
+	"regexp"
+	"time"
+)
+
+// Expr is a node of a parsed --where expression.
+type Expr interface {
+	// Eval reports whether the entry described by m satisfies the expression.
+	Eval(m *Metadata) bool
+}
+
+// And is satisfied when both sides are. The right side is only evaluated when needed.
+type And struct {
+	Left, Right Expr
+}
+
+func (e And) Eval(m *Metadata) bool { return e.Left.Eval(m) && e.Right.Eval(m) }
+
+// Or is satisfied when either side is.
+type Or struct {
+	Left, Right Expr
+}
+
+func (e Or) Eval(m *Metadata) bool { return e.Left.Eval(m) || e.Right.Eval(m) }
+
+// Not inverts its operand.
+type Not struct {
+	X Expr
+}
+
+func (e Not) Eval(m *Metadata) bool { return !e.X.Eval(m) }
+
+// Op is a comparison operator of a condition.
+type Op string
+
+const (
+	OpMatch Op = ":" // Glob match, or equality for fields without globs
+	OpEq    Op = "="
+	OpNe    Op = "!="
+	OpLt    Op = "<"
+	OpLe    Op = "<="
+	OpGt    Op = ">"
+	OpGe    Op = ">="
+	OpRegex Op = "~"
+)
+
+// compare applies an ordering operator to the result of comparing two values (-1, 0 or 1).
+func (op Op) compare(cmp int) bool {
+	switch op {
+	case OpLt:
+		return cmp < 0
+	case OpLe:
+		return cmp <= 0
+	case OpGt:
+		return cmp > 0
+	case OpGe:
+		return cmp >= 0
+	case OpNe:
+		return cmp != 0
+	default:
+		return cmp == 0
+	}
+}
+
+// StringCond compares the base name ("name") or the path relative to the search root ("path").
+type StringCond struct {
+	Field string
+	Op    Op
+	Value string
+	glob  *glob.Glob
+	re    *regexp.Regexp
+}
+
+func (c StringCond) Eval(m *Metadata) bool {
+	s := m.Name
+	if c.Field == "path" {
+		s = m.Rel
+	}
+	switch c.Op {
+	case OpMatch:
+		return c.glob.Match(s)
+	case OpRegex:
+		return c.re.MatchString(s)
+	case OpNe:
+		return s != c.Value
+	default:
+		return s == c.Value
+	}
+}
+
+// ExtCond checks the file extension, case-insensitively.
+type ExtCond struct {
+	Ext    string
+	Negate bool
+}
+
+func (c ExtCond) Eval(m *Metadata) bool {
+	return filters.ExtensionFilter(m.Path, []string{c.Ext}) != c.Negate
+}
+
+// TypeCond checks the file type, as --file-type does.
+type TypeCond struct {
+	Type   string
+	Negate bool
+}
+
+func (c TypeCond) Eval(m *Metadata) bool {
+	info, err := m.Lstat()
+	if err != nil {
+		return false
+	}
+	return filters.MatchFileType(m.FS, m.Path, info, c.Type) != c.Negate
+}
+
+// SizeCond compares the size of regular files. Other entries never match.
+type SizeCond struct {
+	Op    Op
+	Bytes int64
+}
+
+func (c SizeCond) Eval(m *Metadata) bool {
+	info, err := m.Lstat()
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return c.Op.compare(compareInt64(info.Size(), c.Bytes))
+}
+
+// TimeCond compares a timestamp ("mtime", "atime", "ctime" or "btime"). With a duration the
+// age of the entry is compared, so "mtime<7d" means changed less than seven days ago; with a
+// date the timestamp itself is compared, so "mtime<2024-01-01" means changed before 2024.
+type TimeCond struct {
+	Field string
+	Op    Op
+	Age   time.Duration // Set when the value is a duration
+	Date  time.Time     // Set when the value is a date
+	Now   time.Time
+}
+
+func (c TimeCond) Eval(m *Metadata) bool {
+	t, err := m.Time(c.Field)
+	if err != nil {
+		return false
+	}
+	if c.Date.IsZero() {
+		return c.Op.compare(compareInt64(int64(c.Now.Sub(t)), int64(c.Age)))
+	}
+	return c.Op.compare(t.Compare(c.Date))
+}
+
+// OwnerCond checks the user and group, as --owner does.
+type OwnerCond struct {
+	Constraint filters.OwnerConstraint
+	Negate     bool
+}
+
+func (c OwnerCond) Eval(m *Metadata) bool {
+	return filters.OwnerFilter(m.FS, m.Path, c.Constraint) != c.Negate
+}
+
+// PermCond checks the permission bits, as --perm does.
+type PermCond struct {
+	Constraint filters.PermConstraint
+	Negate     bool
+}
+
+func (c PermCond) Eval(m *Metadata) bool {
+	return filters.PermFilter(m.FS, m.Path, c.Constraint) != c.Negate
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
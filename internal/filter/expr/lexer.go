@@ -0,0 +1,138 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+	tokPredicate
+)
+
+// token is a lexed piece of an expression. Predicates like "size>100k" are a single token.
+type token struct {
+	kind  tokenKind
+	pos   int // 1-based column, for error messages
+	field string
+	op    string
+	value string
+}
+
+// operators are tried longest first, so "<=" is not read as "<"
+var operators = []string{"!=", "<=", ">=", ":", "=", "<", ">", "~"}
+
+// lex splits an expression into tokens.
+func lex(src string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokLParen, pos: i + 1})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokRParen, pos: i + 1})
+			i++
+		case strings.HasPrefix(src[i:], "&&"):
+			tokens = append(tokens, token{kind: tokAnd, pos: i + 1})
+			i += 2
+		case strings.HasPrefix(src[i:], "||"):
+			tokens = append(tokens, token{kind: tokOr, pos: i + 1})
+			i += 2
+		case c == '!':
+			tokens = append(tokens, token{kind: tokNot, pos: i + 1})
+			i++
+		case isFieldChar(c):
+			tok, next, err := lexWord(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = next
+		default:
+			return nil, fmt.Errorf("unexpected %q at column %d", c, i+1)
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, pos: len(src) + 1}), nil
+}
+
+// lexWord reads a keyword or a predicate starting at src[start].
+func lexWord(src string, start int) (token, int, error) {
+	i := start
+	for i < len(src) && isFieldChar(src[i]) {
+		i++
+	}
+	word := src[start:i]
+
+	op := ""
+	for _, candidate := range operators {
+		if strings.HasPrefix(src[i:], candidate) {
+			op = candidate
+			break
+		}
+	}
+
+	if op == "" {
+		switch strings.ToLower(word) {
+		case "and":
+			return token{kind: tokAnd, pos: start + 1}, i, nil
+		case "or":
+			return token{kind: tokOr, pos: start + 1}, i, nil
+		case "not":
+			return token{kind: tokNot, pos: start + 1}, i, nil
+		}
+		return token{}, 0, fmt.Errorf("expected a condition like name:*.go or size>10k at column %d, found %q", start+1, word)
+	}
+	i += len(op)
+
+	value, next, err := lexValue(src, i)
+	if err != nil {
+		return token{}, 0, err
+	}
+	return token{kind: tokPredicate, pos: start + 1, field: strings.ToLower(word), op: op, value: value}, next, nil
+}
+
+// lexValue reads a predicate's value: a quoted string, or everything up to whitespace or ")".
+func lexValue(src string, start int) (string, int, error) {
+	if start < len(src) && (src[start] == '"' || src[start] == '\'') {
+		quote := src[start]
+		var sb strings.Builder
+		for i := start + 1; i < len(src); i++ {
+			switch {
+			case src[i] == '\\' && i+1 < len(src) && (src[i+1] == quote || src[i+1] == '\\'):
+				i++
+				sb.WriteByte(src[i])
+			case src[i] == quote:
+				return sb.String(), i + 1, nil
+			default:
+				sb.WriteByte(src[i])
+			}
+		}
+		return "", 0, fmt.Errorf("unterminated quote starting at column %d", start+1)
+	}
+
+	i := start
+	for i < len(src) && src[i] != ' ' && src[i] != '\t' && src[i] != '\n' && src[i] != ')' {
+		i++
+	}
+	if i == start {
+		return "", 0, fmt.Errorf("missing value at column %d", start+1)
+	}
+	return src[start:i], i, nil
+}
+
+func isFieldChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
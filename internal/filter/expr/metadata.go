@@ -0,0 +1,59 @@
+package expr
+
+import (
+	"gofs/internal/filter/filters"
+	"gofs/internal/fsys"
+	"gofs/internal/traverse"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Metadata describes one entry to an expression. File information is loaded on first use,
+// so expressions that only look at names never touch the file system.
+type Metadata struct {
+	Path string  // Path as displayed
+	Rel  string  // Path relative to the search root, slash-separated
+	Name string  // Base name
+	FS   fsys.FS // File system the entry was found in
+
+	lstat     fs.FileInfo
+	lstatErr  error
+	lstatDone bool
+}
+
+// NewMetadata describes a streamed entry.
+func NewMetadata(entry traverse.Entry) *Metadata {
+	rel, err := filepath.Rel(entry.Root, entry.Path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(entry.Path)
+	}
+	return &Metadata{
+		Path: entry.Path,
+		Rel:  filepath.ToSlash(rel),
+		Name: filepath.Base(entry.Path),
+		FS:   entry.FS,
+	}
+}
+
+// Lstat returns the entry's file information without following symbolic links.
+func (m *Metadata) Lstat() (fs.FileInfo, error) {
+	if !m.lstatDone {
+		m.lstat, m.lstatErr = m.FS.Lstat(m.Path)
+		m.lstatDone = true
+	}
+	return m.lstat, m.lstatErr
+}
+
+// Time returns one of the entry's timestamps: "mtime", "atime", "ctime" or "btime".
+func (m *Metadata) Time(field string) (time.Time, error) {
+	if field == "mtime" {
+		info, err := m.Lstat()
+		if err != nil {
+			return time.Time{}, err
+		}
+		return info.ModTime(), nil
+	}
+	return filters.FileTime(m.FS, m.Path, field)
+}
@@ -0,0 +1,263 @@
+// Package expr implements the --where expression language, a readable alternative to chains of
+// find predicates:
+//
+//	ext:go and (size>100k or mtime<7d) and not path:vendor/**
+//
+// A condition is a field, an operator and a value. name and path take a glob (":"), an exact
+// value ("=", "!=") or a regex ("~"); path is relative to the search root. ext, type, owner and
+// perm take the same values as the matching flags with ":", "=" or "!=". size compares bytes with
+// units, and mtime, atime, ctime and btime compare the age with a duration or the time with a date.
+//
+// Conditions are combined with "and" (or "&&", or nothing at all), "or" ("||"), "not" ("!") and
+// parentheses; "not" binds tightest, then "and", then "or". Values containing spaces or ")" can
+// be wrapped in double or single quotes.
+package expr
+
+import (
+	"fmt"
+	"gofs/internal/filter/filters"
+	"gofs/internal/glob"
+	"gofs/utils"
+	"regexp"
+	"time"
+)
+
+// Parse compiles an expression. Durations in time conditions are measured back from now.
+func Parse(src string, now time.Time) (Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %v", err)
+	}
+
+	p := &parser{tokens: tokens, now: now}
+	if p.peek().kind == tokEOF {
+		return nil, fmt.Errorf("invalid expression: it is empty")
+	}
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %v", err)
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("invalid expression: unexpected %s at column %d", describe(tok), tok.pos)
+	}
+	return e, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	now    time.Time
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr parses: and ("or" and)*
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: not (["and"] not)*, where two conditions side by side are ANDed.
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek().kind {
+		case tokAnd:
+			p.next()
+		case tokNot, tokLParen, tokPredicate:
+		default:
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+}
+
+// parseNot parses: "not" not | primary
+func (p *parser) parseNot() (Expr, error) {
+	if p.peek().kind == tokNot {
+		p.next()
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return Not{X: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses: "(" or ")" | condition
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokLParen:
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, fmt.Errorf("missing ) for the ( at column %d, found %s", tok.pos, describe(closing))
+		}
+		return e, nil
+	case tokPredicate:
+		e, err := p.condition(tok)
+		if err != nil {
+			return nil, fmt.Errorf("%s at column %d: %v", tok.field+tok.op+tok.value, tok.pos, err)
+		}
+		return e, nil
+	default:
+		return nil, fmt.Errorf("expected a condition at column %d, found %s", tok.pos, describe(tok))
+	}
+}
+
+// condition compiles a single field, operator and value into a typed node.
+func (p *parser) condition(tok token) (Expr, error) {
+	op := Op(tok.op)
+	value := tok.value
+
+	switch tok.field {
+	case "name", "path":
+		c := StringCond{Field: tok.field, Op: op, Value: value}
+		switch op {
+		case OpMatch:
+			g, err := glob.Compile(value)
+			if err != nil {
+				return nil, err
+			}
+			c.glob = g
+		case OpRegex:
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex: %v", err)
+			}
+			c.re = re
+		case OpEq, OpNe:
+		default:
+			return nil, unsupported(tok, ":, =, != or ~")
+		}
+		return c, nil
+
+	case "ext":
+		if !isEquality(op) {
+			return nil, unsupported(tok, ":, = or !=")
+		}
+		return ExtCond{Ext: value, Negate: op == OpNe}, nil
+
+	case "type":
+		if !isEquality(op) {
+			return nil, unsupported(tok, ":, = or !=")
+		}
+		if err := utils.ValidateFileType(value); err != nil {
+			return nil, err
+		}
+		return TypeCond{Type: value, Negate: op == OpNe}, nil
+
+	case "size":
+		if op == OpRegex {
+			return nil, unsupported(tok, ":, =, !=, <, <=, > or >=")
+		}
+		if op == OpMatch {
+			op = OpEq
+		}
+		bytes, err := utils.ParseSize(value)
+		if err != nil {
+			return nil, err
+		}
+		return SizeCond{Op: op, Bytes: bytes}, nil
+
+	case "mtime", "atime", "ctime", "btime":
+		switch op {
+		case OpLt, OpLe, OpGt, OpGe:
+		default:
+			return nil, unsupported(tok, "<, <=, > or >=")
+		}
+		c := TimeCond{Field: tok.field, Op: op, Now: p.now}
+		if age, err := utils.ParseDuration(value); err == nil {
+			c.Age = age
+			return c, nil
+		}
+		date, err := utils.ParsePointInTime(value, p.now)
+		if err != nil {
+			return nil, err
+		}
+		c.Date = date
+		return c, nil
+
+	case "owner":
+		if !isEquality(op) {
+			return nil, unsupported(tok, ":, = or !=")
+		}
+		constraint, err := filters.ParseOwnerConstraint(value)
+		if err != nil {
+			return nil, err
+		}
+		return OwnerCond{Constraint: constraint, Negate: op == OpNe}, nil
+
+	case "perm":
+		if !isEquality(op) {
+			return nil, unsupported(tok, ":, = or !=")
+		}
+		constraint, err := filters.ParsePermConstraint(value)
+		if err != nil {
+			return nil, err
+		}
+		return PermCond{Constraint: constraint, Negate: op == OpNe}, nil
+	}
+
+	return nil, fmt.Errorf("unknown field %q, expected name, path, ext, type, size, mtime, atime, ctime, btime, owner or perm", tok.field)
+}
+
+func isEquality(op Op) bool {
+	return op == OpMatch || op == OpEq || op == OpNe
+}
+
+func unsupported(tok token, supported string) error {
+	return fmt.Errorf("%s does not support %s, use %s", tok.field, tok.op, supported)
+}
+
+// describe names a token in error messages.
+func describe(tok token) string {
+	switch tok.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokLParen:
+		return `"("`
+	case tokRParen:
+		return `")"`
+	case tokAnd:
+		return `"and"`
+	case tokOr:
+		return `"or"`
+	case tokNot:
+		return `"not"`
+	}
+	return fmt.Sprintf("%q", tok.field+tok.op+tok.value)
+}
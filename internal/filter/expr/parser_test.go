@@ -0,0 +1,117 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// show prints the structure of an expression, with every operator parenthesized.
+func show(e Expr) string {
+	switch e := e.(type) {
+	case And:
+		return "(" + show(e.Left) + " and " + show(e.Right) + ")"
+	case Or:
+		return "(" + show(e.Left) + " or " + show(e.Right) + ")"
+	case Not:
+		return "not " + show(e.X)
+	case StringCond:
+		return e.Field + string(e.Op) + e.Value
+	case ExtCond:
+		if e.Negate {
+			return "ext!=" + e.Ext
+		}
+		return "ext:" + e.Ext
+	case SizeCond:
+		return fmt.Sprintf("size%s%d", e.Op, e.Bytes)
+	case TypeCond:
+		return "type:" + e.Type
+	case TimeCond:
+		return e.Field + string(e.Op) + e.Age.String()
+	}
+	return fmt.Sprintf("%T", e)
+}
+
+func TestParsePrecedence(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"name:a", "name:a"},
+		{"name:a and name:b or name:c", "((name:a and name:b) or name:c)"},
+		{"name:a or name:b and name:c", "(name:a or (name:b and name:c))"},
+		{"not name:a and name:b", "(not name:a and name:b)"},
+		{"not name:a or name:b", "(not name:a or name:b)"},
+		{"not not name:a", "not not name:a"},
+		{"not (name:a or name:b)", "not (name:a or name:b)"},
+		{"(name:a or name:b) and name:c", "((name:a or name:b) and name:c)"},
+		{"name:a or name:b or name:c", "((name:a or name:b) or name:c)"},
+
+		// Implicit "and", symbolic operators and keyword case
+		{"name:a name:b or name:c", "((name:a and name:b) or name:c)"},
+		{"name:a && name:b || !name:c", "((name:a and name:b) or not name:c)"},
+		{"name:a AND NOT name:b", "(name:a and not name:b)"},
+
+		// Values
+		{"ext:go and (size>100k or mtime<7d)", "(ext:go and (size>100000 or mtime<168h0m0s))"},
+		{"size<=1k", "size<=1000"},
+		{"size<=1Ki", "size<=1024"},
+		{"size:10", "size=10"},
+		{"ext!=go", "ext!=go"},
+		{`name:"my notes.md"`, "name:my notes.md"},
+		{`name:'a)b'`, "name:a)b"},
+		{`name:"say \"hi\""`, `name:say "hi"`},
+		{"path~^src/.*_test\\.go$", "path~^src/.*_test\\.go$"},
+		{"NAME:a", "name:a"},
+	}
+
+	for _, tt := range tests {
+		e, err := Parse(tt.src, time.Now())
+		if err != nil {
+			t.Errorf("Parse(%q): unexpected error: %v", tt.src, err)
+			continue
+		}
+		if got := show(e); got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string // Part of the error message
+	}{
+		{"", "it is empty"},
+		{"   ", "it is empty"},
+		{"name:a )", `unexpected ")" at column 8`},
+		{"name:(a)", `unexpected ")" at column 8`}, // Unquoted values end at ")"
+		{"(name:a", "missing ) for the ( at column 1, found end of expression"},
+		{"name:a and (name:b or", "expected a condition at column 22, found end of expression"},
+		{"name:a or or name:b", `expected a condition at column 11, found "or"`},
+		{"foo", `expected a condition like name:*.go or size>10k at column 1, found "foo"`},
+		{"name:a # b", `unexpected '#' at column 8`},
+		{"name:", "missing value at column 6"},
+		{`name:"abc`, "unterminated quote starting at column 6"},
+		{"color:red", `unknown field "color"`},
+		{"name:a and size~10", "size~10 at column 12: size does not support ~"},
+		{"mtime:7d", "mtime:7d at column 1: mtime does not support :"},
+		{"ext<go", "ext does not support <"},
+		{"type:banana", "type:banana at column 1"},
+		{"size>lots", "size>lots at column 1"},
+		{"name:[abc", "name:[abc at column 1"},
+		{"path~(", "path~( at column 1: invalid regex"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.src, time.Now())
+		if err == nil {
+			t.Errorf("Parse(%q): expected an error", tt.src)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), "invalid expression: ") || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.src, err, tt.want)
+		}
+	}
+}
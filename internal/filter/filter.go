@@ -1,37 +1,93 @@
 package filter
 
 import (
-	"fmt"
-	"gofs/internal/filter/filters"
+	"context"
+	"gofs/internal/registry"
+	"gofs/internal/traverse"
+	"time"
 )
 
-func FilterResults(searchResults []string, filterOptions map[string]interface{}) ([]string, error) {
-	filteredResults := searchResults
-	var err error
+// Filter decides whether a search result is kept.
+type Filter interface {
+	Keep(entry traverse.Entry) bool
+}
+
+// Func adapts a function to the Filter interface.
+type Func func(entry traverse.Entry) bool
+
+func (f Func) Keep(entry traverse.Entry) bool { return f(entry) }
+
+// Options holds the filter flags. Empty values leave the corresponding filter out.
+type Options struct {
+	Extensions    []string  // -e, matched case-insensitively
+	FileTypes     []string  // -t, any of them matches
+	Excludes      []string  // -x globs
+	Sizes         []string  // --size constraints, all of them must hold
+	ChangedWithin string    // --changed-within duration or date
+	ChangedBefore string    // --changed-before duration or date
+	Newer         string    // --newer reference file
+	Older         string    // --older reference file
+	TimeField     string    // --time-field, mtime when empty
+	Owner         string    // --owner user[:group]
+	Perm          string    // --perm mode
+	Where         string    // --where expression
+	Now           time.Time // Time relative durations are measured back from, the time of Build when zero
+}
+
+// Builder creates a filter from the options, reporting false when the options do not ask for it.
+type Builder = registry.Builder[Options, Filter]
+
+var filterRegistry registry.Registry[Options, Filter]
+
+// Register adds a filter to every search. Filters run by ascending priority, then by name;
+// the built-in ones leave gaps of ten so others can be placed between them. Cheap filters
+// that only look at the path should come before filters that stat the file.
+func Register(name string, priority int, build Builder) {
+	filterRegistry.Register(name, priority, build)
+}
+
+// Registered lists the names of the registered filters in the order they run.
+func Registered() []string {
+	return filterRegistry.Names()
+}
+
+// Build validates the options and returns the active filters in their running order.
+func Build(opts Options) ([]Filter, error) {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	return filterRegistry.Build(opts)
+}
+
+// FilterResults passes every streamed search result through the filters and sends the results
+// that pass all of them to the returned channel. Filters are checked in order and stop at the first rejection.
+func FilterResults(ctx context.Context, searchResults <-chan traverse.Entry, filters []Filter) <-chan traverse.Entry {
+	filteredResults := make(chan traverse.Entry, cap(searchResults))
 
 	// Apply filters one by one
-	for key, value := range filterOptions {
-		switch key {
-		case "Extension":
-			if ext, ok := value.(string); ok && ext != "" {
-				filteredResults = filters.ExtensionFilter(filteredResults, ext)
-			}
-		case "FileType":
-			if fileType, ok := value.(string); ok && fileType != "" {
-				filteredResults, err = filters.FileTypeFilter(filteredResults, fileType)
-				if err != nil {
-					return nil, fmt.Errorf("error applying file type filter: %v", err)
-				}
+	go func() {
+		defer close(filteredResults)
+		for entry := range searchResults {
+			if !passesAll(entry, filters) {
+				continue
 			}
-		case "Exclude":
-			if excludePattern, ok := value.(string); ok && excludePattern != "" {
-				filteredResults, err = filters.ExcludeFilter(filteredResults, excludePattern)
-				if err != nil {
-					return nil, fmt.Errorf("error applying exclude filter: %v", err)
-				}
+			select {
+			case filteredResults <- entry:
+			case <-ctx.Done():
+				return
 			}
 		}
-	}
+	}()
+
+	return filteredResults
+}
 
-	return filteredResults, nil
+// passesAll reports whether a result passes every active filter.
+func passesAll(entry traverse.Entry, filters []Filter) bool {
+	for _, f := range filters {
+		if !f.Keep(entry) {
+			return false
+		}
+	}
+	return true
 }
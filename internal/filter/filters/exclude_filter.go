@@ -1,25 +1,45 @@
 package filters
 
 import (
-	"fmt"
-	"gofs/utils"
+	"gofs/internal/glob"
 	"path/filepath"
+	"strings"
 )
 
-func ExcludeFilter(results []string, pattern string) ([]string, error) {
-	if !utils.IsValidGlob(pattern) {
-		return nil, fmt.Errorf("invalid glob pattern: %s", pattern)
+// ExcludePattern is a compiled --exclude glob. Like gitignore, a pattern containing "/" matches
+// the path relative to the search root, any other pattern matches a single name.
+type ExcludePattern struct {
+	glob     *glob.Glob
+	pathWide bool
+}
+
+// CompileExcludePattern compiles an --exclude glob.
+func CompileExcludePattern(pattern string) (ExcludePattern, error) {
+	g, err := glob.Compile(strings.Trim(pattern, "/"))
+	if err != nil {
+		return ExcludePattern{}, err
+	}
+	return ExcludePattern{glob: g, pathWide: strings.Contains(strings.Trim(pattern, "/"), "/")}, nil
+}
+
+// ExcludeFilter reports whether a result should be kept, i.e. neither it nor any of the directories
+// between it and its search root match one of the exclude patterns.
+func ExcludeFilter(file, root string, patterns []ExcludePattern) bool {
+	rel, err := filepath.Rel(root, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(file)
 	}
+	rel = filepath.ToSlash(rel)
 
-	var filtered []string
-	for _, file := range results {
-		matched, err := filepath.Match(pattern, filepath.Base(file))
-		if err != nil {
-			return nil, fmt.Errorf("error matching glob pattern: %v", err)
-		}
-		if !matched {
-			filtered = append(filtered, file)
+	// Check every leading part of the relative path, so excluding a directory excludes its contents
+	parts := strings.Split(rel, "/")
+	for i, part := range parts {
+		prefix := strings.Join(parts[:i+1], "/")
+		for _, p := range patterns {
+			if p.pathWide && p.glob.Match(prefix) || !p.pathWide && p.glob.Match(part) {
+				return false
+			}
 		}
 	}
-	return filtered, nil
+	return true
 }
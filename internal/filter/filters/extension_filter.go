@@ -1,14 +1,20 @@
 package filters
 
-import "strings"
+import (
+	"path/filepath"
+	"strings"
+)
 
-// extensionFilter filters results by file extension.
-func ExtensionFilter(results []string, ext string) []string {
-	var filtered []string
-	for _, file := range results {
-		if strings.HasSuffix(file, "."+ext) {
-			filtered = append(filtered, file)
+// ExtensionFilter reports whether a result has any of the given file extensions. Extensions are
+// compared case-insensitively and may have several parts, like "tar.gz". A leading "." is optional.
+func ExtensionFilter(file string, exts []string) bool {
+	name := strings.ToLower(filepath.Base(file))
+	for _, ext := range exts {
+		suffix := "." + strings.ToLower(strings.TrimPrefix(ext, "."))
+		// The name needs more than the extension, so ".gitignore" has no extension "gitignore"
+		if len(name) > len(suffix) && strings.HasSuffix(name, suffix) {
+			return true
 		}
 	}
-	return filtered
+	return false
 }
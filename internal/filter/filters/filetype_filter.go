@@ -1,33 +1,79 @@
 package filters
 
 import (
-	"fmt"
-	"os"
+	"gofs/internal/fsys"
+	"io/fs"
 )
 
-func FileTypeFilter(results []string, fileType string) ([]string, error) {
-	var filtered []string
-	for _, file := range results {
-		info, err := os.Stat(file)
-		if err != nil {
-			continue // Skip invalid paths
+// FileTypeFilter reports whether a result is of any of the given file types.
+// The file types must be validated beforehand. Symbolic links are not followed,
+// so a link is a "symlink" and never a "file" or "dir".
+func FileTypeFilter(fileSystem fsys.FS, file string, fileTypes []string) bool {
+	info, err := fileSystem.Lstat(file)
+	if err != nil {
+		return false // Skip invalid paths
+	}
+	for _, fileType := range fileTypes {
+		if MatchFileType(fileSystem, file, info, fileType) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchFileType checks a single file type against the Lstat result of file.
+func MatchFileType(fileSystem fsys.FS, file string, info fs.FileInfo, fileType string) bool {
+	mode := info.Mode()
+	switch fileType {
+	case "file":
+		return mode.IsRegular()
+	case "dir":
+		return mode.IsDir()
+	case "symlink":
+		return mode&fs.ModeSymlink != 0
+	case "socket":
+		return mode&fs.ModeSocket != 0
+	case "pipe":
+		return mode&fs.ModeNamedPipe != 0
+	case "char-device":
+		return mode&fs.ModeDevice != 0 && mode&fs.ModeCharDevice != 0
+	case "block-device":
+		return mode&fs.ModeDevice != 0 && mode&fs.ModeCharDevice == 0
+	case "executable":
+		return mode.IsRegular() && mode.Perm()&0o111 != 0
+	case "empty":
+		if mode.IsRegular() {
+			return info.Size() == 0
 		}
-		switch fileType {
-		case "file":
-			if info.Mode().IsRegular() {
-				filtered = append(filtered, file)
-			}
-		case "dir":
-			if info.IsDir() {
-				filtered = append(filtered, file)
-			}
-		case "symlink":
-			if info.Mode()&os.ModeSymlink != 0 {
-				filtered = append(filtered, file)
-			}
-		default:
-			return nil, fmt.Errorf("invalid file type: %s", fileType)
+		return mode.IsDir() && fsys.IsEmptyDir(fileSystem, file)
+	case "broken-symlink":
+		if mode&fs.ModeSymlink == 0 {
+			return false
 		}
+		_, err := fileSystem.Stat(file) // Fails when the link target does not exist
+		return err != nil
+	}
+	return false
+}
+
+// FileTypeName names the type of a file the way --file-type does: file, dir, symlink, socket,
+// pipe, char-device or block-device. Other files, like Windows reparse points, have no name.
+func FileTypeName(mode fs.FileMode) string {
+	switch {
+	case mode.IsRegular():
+		return "file"
+	case mode.IsDir():
+		return "dir"
+	case mode&fs.ModeSymlink != 0:
+		return "symlink"
+	case mode&fs.ModeSocket != 0:
+		return "socket"
+	case mode&fs.ModeNamedPipe != 0:
+		return "pipe"
+	case mode&fs.ModeDevice != 0 && mode&fs.ModeCharDevice != 0:
+		return "char-device"
+	case mode&fs.ModeDevice != 0:
+		return "block-device"
 	}
-	return filtered, nil
+	return ""
 }
@@ -0,0 +1,101 @@
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"gofs/internal/fsys"
+	"os/user"
+	"strconv"
+	"strings"
+)
+
+// OwnerConstraint is a parsed --owner argument. An ID of -1 matches any owner.
+type OwnerConstraint struct {
+	UID         int64
+	GID         int64
+	NegateUser  bool // Keep files whose user is not UID
+	NegateGroup bool // Keep files whose group is not GID
+}
+
+// ParseOwnerConstraint parses "user", "user:group", ":group" or "user:", where users and groups
+// are names or numeric IDs. Either side can be prefixed with "!" to exclude instead, e.g. "!root".
+func ParseOwnerConstraint(owner string) (OwnerConstraint, error) {
+	if !ownershipSupported {
+		return OwnerConstraint{}, errors.New("ownership filters are not supported on this platform")
+	}
+
+	userPart, groupPart, _ := strings.Cut(owner, ":")
+	if userPart == "" && groupPart == "" {
+		return OwnerConstraint{}, fmt.Errorf("invalid owner: %q, expected user, user:group or :group", owner)
+	}
+
+	c := OwnerConstraint{UID: -1, GID: -1}
+
+	if userPart != "" {
+		name, negate := strings.CutPrefix(userPart, "!")
+		uid, err := lookupID(name, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		})
+		if err != nil {
+			return OwnerConstraint{}, fmt.Errorf("invalid owner: %q, unknown user %q", owner, name)
+		}
+		c.UID, c.NegateUser = uid, negate
+	}
+
+	if groupPart != "" {
+		name, negate := strings.CutPrefix(groupPart, "!")
+		gid, err := lookupID(name, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		})
+		if err != nil {
+			return OwnerConstraint{}, fmt.Errorf("invalid owner: %q, unknown group %q", owner, name)
+		}
+		c.GID, c.NegateGroup = gid, negate
+	}
+
+	return c, nil
+}
+
+// lookupID returns a numeric ID as is and resolves a name with lookup.
+func lookupID(name string, lookup func(string) (string, error)) (int64, error) {
+	if name == "" {
+		return 0, errors.New("empty name")
+	}
+	if id, err := strconv.ParseUint(name, 10, 32); err == nil {
+		return int64(id), nil
+	}
+	id, err := lookup(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(id, 10, 64)
+}
+
+// OwnerFilter reports whether a result's user and group satisfy the constraint. Symbolic links
+// are judged by their own owner, not their target's, like find's -user and -group.
+func OwnerFilter(fileSystem fsys.FS, file string, constraint OwnerConstraint) bool {
+	info, err := fileSystem.Lstat(file)
+	if err != nil {
+		return false // Skip invalid paths
+	}
+	uid, gid, ok := FileOwner(info)
+	if !ok {
+		return false
+	}
+
+	if constraint.UID != -1 && (int64(uid) == constraint.UID) == constraint.NegateUser {
+		return false
+	}
+	if constraint.GID != -1 && (int64(gid) == constraint.GID) == constraint.NegateGroup {
+		return false
+	}
+	return true
+}
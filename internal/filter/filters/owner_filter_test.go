@@ -0,0 +1,103 @@
+package filters
+
+import (
+	"gofs/internal/fsys"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseOwnerConstraint(t *testing.T) {
+	if !ownershipSupported {
+		t.Skip("ownership filters are not supported on this platform")
+	}
+	current, err := user.Current()
+	if err != nil {
+		t.Skipf("cannot look up the current user: %v", err)
+	}
+	group, err := user.LookupGroupId(current.Gid)
+	if err != nil {
+		t.Skipf("cannot look up the current group: %v", err)
+	}
+	uid, _ := strconv.ParseInt(current.Uid, 10, 64)
+	gid, _ := strconv.ParseInt(current.Gid, 10, 64)
+
+	tests := []struct {
+		owner string
+		want  OwnerConstraint
+	}{
+		{current.Username, OwnerConstraint{UID: uid, GID: -1}},
+		{current.Uid, OwnerConstraint{UID: uid, GID: -1}},
+		{"12345", OwnerConstraint{UID: 12345, GID: -1}}, // Numeric IDs need no account
+		{current.Username + ":" + group.Name, OwnerConstraint{UID: uid, GID: gid}},
+		{current.Uid + ":" + current.Gid, OwnerConstraint{UID: uid, GID: gid}},
+		{current.Username + ":", OwnerConstraint{UID: uid, GID: -1}},
+		{":" + group.Name, OwnerConstraint{UID: -1, GID: gid}},
+		{"!" + current.Username, OwnerConstraint{UID: uid, GID: -1, NegateUser: true}},
+		{":!" + current.Gid, OwnerConstraint{UID: -1, GID: gid, NegateGroup: true}},
+		{"!12345:!" + group.Name, OwnerConstraint{UID: 12345, GID: gid, NegateUser: true, NegateGroup: true}},
+	}
+	for _, tt := range tests {
+		got, err := ParseOwnerConstraint(tt.owner)
+		if err != nil {
+			t.Errorf("ParseOwnerConstraint(%q): unexpected error: %v", tt.owner, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseOwnerConstraint(%q) = %+v, want %+v", tt.owner, got, tt.want)
+		}
+	}
+}
+
+func TestParseOwnerConstraintErrors(t *testing.T) {
+	if !ownershipSupported {
+		t.Skip("ownership filters are not supported on this platform")
+	}
+	tests := []struct {
+		owner string
+		want  string // Part of the error message
+	}{
+		{"", "expected user, user:group or :group"},
+		{":", "expected user, user:group or :group"},
+		{"no-such-user-gofs", `unknown user "no-such-user-gofs"`},
+		{":no-such-group-gofs", `unknown group "no-such-group-gofs"`},
+		{"!", `unknown user ""`},
+		{"-1", `unknown user "-1"`},
+	}
+	for _, tt := range tests {
+		_, err := ParseOwnerConstraint(tt.owner)
+		if err == nil {
+			t.Errorf("ParseOwnerConstraint(%q): expected an error", tt.owner)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseOwnerConstraint(%q) error = %q, want it to contain %q", tt.owner, err, tt.want)
+		}
+	}
+}
+
+// TestOwnerFilterBrokenSymlink checks that a broken link is judged by its own owner instead of being dropped.
+func TestOwnerFilterBrokenSymlink(t *testing.T) {
+	if !ownershipSupported {
+		t.Skip("ownership filters are not supported on this platform")
+	}
+	broken := filepath.Join(t.TempDir(), "broken")
+	if err := os.Symlink("missing", broken); err != nil {
+		t.Skipf("cannot create symbolic links: %v", err)
+	}
+	info, err := os.Lstat(broken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uid, gid, _ := FileOwner(info)
+
+	if !OwnerFilter(fsys.OS, broken, OwnerConstraint{UID: int64(uid), GID: int64(gid)}) {
+		t.Error("broken link does not match its own owner")
+	}
+	if OwnerFilter(fsys.OS, broken, OwnerConstraint{UID: int64(uid), GID: -1, NegateUser: true}) {
+		t.Error("broken link matches an owner it excludes")
+	}
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package filters
+
+import (
+	"os"
+	"syscall"
+)
+
+const ownershipSupported = true
+
+// FileOwner returns the user and group IDs from the stat result.
+func FileOwner(info os.FileInfo) (uid, gid uint32, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return st.Uid, st.Gid, true
+}
@@ -0,0 +1,151 @@
+package filters
+
+import (
+	"fmt"
+	"gofs/internal/fsys"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// How a permission constraint compares the mode bits, following find's -perm
+const (
+	PermExact = iota // "0644": the bits are exactly these
+	PermAll          // "-u+x": all of these bits are set
+	PermAny          // "/o+w": any of these bits is set
+)
+
+// PermConstraint is a parsed --perm argument. Mode holds Unix permission bits, including
+// setuid (04000), setgid (02000) and sticky (01000).
+type PermConstraint struct {
+	Mode  uint32
+	Match int
+}
+
+// ParsePermConstraint parses find-style permissions: an octal ("0644") or symbolic ("u=rw,go=r")
+// mode, prefixed with "-" to require all of its bits or "/" to require any of them.
+func ParsePermConstraint(perm string) (PermConstraint, error) {
+	c := PermConstraint{Match: PermExact}
+	mode := perm
+	if rest, ok := strings.CutPrefix(mode, "-"); ok {
+		c.Match, mode = PermAll, rest
+	} else if rest, ok := strings.CutPrefix(mode, "/"); ok {
+		c.Match, mode = PermAny, rest
+	}
+
+	if mode == "" {
+		return PermConstraint{}, fmt.Errorf("invalid permissions: %q, expected an octal mode like 0644 or a symbolic mode like u+x, optionally prefixed with - or /", perm)
+	}
+
+	if mode[0] >= '0' && mode[0] <= '9' {
+		bits, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil || bits > 0o7777 {
+			return PermConstraint{}, fmt.Errorf("invalid permissions: %q, %q is not an octal mode between 0 and 7777", perm, mode)
+		}
+		c.Mode = uint32(bits)
+		return c, nil
+	}
+
+	bits, err := parseSymbolicMode(mode)
+	if err != nil {
+		return PermConstraint{}, fmt.Errorf("invalid permissions: %q, %v", perm, err)
+	}
+	c.Mode = bits
+	return c, nil
+}
+
+// parseSymbolicMode applies comma-separated clauses like "u+x", "go=r" or "a-w" to an empty mode.
+func parseSymbolicMode(mode string) (uint32, error) {
+	var bits uint32
+	for _, clause := range strings.Split(mode, ",") {
+		// Who the clause applies to, everyone when omitted
+		i := 0
+		var who uint32
+		for ; i < len(clause) && strings.IndexByte("ugoa", clause[i]) != -1; i++ {
+			switch clause[i] {
+			case 'u':
+				who |= 0o4700
+			case 'g':
+				who |= 0o2070
+			case 'o':
+				who |= 0o1007
+			case 'a':
+				who |= 0o7777
+			}
+		}
+		if who == 0 {
+			who = 0o7777
+		}
+
+		if i == len(clause) || strings.IndexByte("+-=", clause[i]) == -1 {
+			return 0, fmt.Errorf("clause %q has no +, - or = operator", clause)
+		}
+		op := clause[i]
+		if i+1 == len(clause) {
+			return 0, fmt.Errorf("clause %q has no permissions, expected r, w, x, s or t after %q", clause, op)
+		}
+
+		var perms uint32
+		for _, p := range clause[i+1:] {
+			switch p {
+			case 'r':
+				perms |= 0o444
+			case 'w':
+				perms |= 0o222
+			case 'x':
+				perms |= 0o111
+			case 's':
+				perms |= 0o6000
+			case 't':
+				perms |= 0o1000
+			default:
+				return 0, fmt.Errorf("clause %q has an unknown permission %q, expected r, w, x, s or t", clause, p)
+			}
+		}
+		perms &= who
+
+		switch op {
+		case '+':
+			bits |= perms
+		case '-':
+			bits &^= perms
+		case '=':
+			bits = bits&^who | perms
+		}
+	}
+	return bits, nil
+}
+
+// PermFilter reports whether a result's permission bits satisfy the constraint. Symbolic links
+// are judged by their own bits, not their target's, like find's -perm.
+func PermFilter(fileSystem fsys.FS, file string, constraint PermConstraint) bool {
+	info, err := fileSystem.Lstat(file)
+	if err != nil {
+		return false // Skip invalid paths
+	}
+
+	mode := UnixMode(info.Mode())
+	switch constraint.Match {
+	case PermAll:
+		return mode&constraint.Mode == constraint.Mode
+	case PermAny:
+		return constraint.Mode == 0 || mode&constraint.Mode != 0
+	default:
+		return mode == constraint.Mode
+	}
+}
+
+// UnixMode converts Go's file mode into Unix permission bits.
+func UnixMode(m fs.FileMode) uint32 {
+	bits := uint32(m.Perm())
+	if m&fs.ModeSetuid != 0 {
+		bits |= 0o4000
+	}
+	if m&fs.ModeSetgid != 0 {
+		bits |= 0o2000
+	}
+	if m&fs.ModeSticky != 0 {
+		bits |= 0o1000
+	}
+	return bits
+}
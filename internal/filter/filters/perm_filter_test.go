@@ -0,0 +1,103 @@
+package filters
+
+import (
+	"gofs/internal/fsys"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePermConstraint(t *testing.T) {
+	tests := []struct {
+		perm string
+		want PermConstraint
+	}{
+		{"0644", PermConstraint{Mode: 0o644, Match: PermExact}},
+		{"644", PermConstraint{Mode: 0o644, Match: PermExact}},
+		{"4755", PermConstraint{Mode: 0o4755, Match: PermExact}},
+		{"-u+x", PermConstraint{Mode: 0o100, Match: PermAll}},
+		{"/o+w", PermConstraint{Mode: 0o002, Match: PermAny}},
+		{"/222", PermConstraint{Mode: 0o222, Match: PermAny}},
+		{"u=rw,go=r", PermConstraint{Mode: 0o644, Match: PermExact}},
+		{"a+x", PermConstraint{Mode: 0o111, Match: PermExact}},
+		{"+x", PermConstraint{Mode: 0o111, Match: PermExact}}, // Everyone when who is omitted
+		{"ug+rwx,o+rx", PermConstraint{Mode: 0o775, Match: PermExact}},
+		{"a=rwx,o-w", PermConstraint{Mode: 0o775, Match: PermExact}},
+		{"u+s", PermConstraint{Mode: 0o4000, Match: PermExact}},
+		{"g+s", PermConstraint{Mode: 0o2000, Match: PermExact}},
+		{"+t", PermConstraint{Mode: 0o1000, Match: PermExact}},
+	}
+	for _, tt := range tests {
+		got, err := ParsePermConstraint(tt.perm)
+		if err != nil {
+			t.Errorf("ParsePermConstraint(%q): unexpected error: %v", tt.perm, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParsePermConstraint(%q) = %04o/%d, want %04o/%d", tt.perm, got.Mode, got.Match, tt.want.Mode, tt.want.Match)
+		}
+	}
+}
+
+func TestParsePermConstraintErrors(t *testing.T) {
+	tests := []struct {
+		perm string
+		want string // Part of the error message
+	}{
+		{"", "expected an octal mode"},
+		{"-", "expected an octal mode"},
+		{"0999", "not an octal mode"},
+		{"17777", "not an octal mode"},
+		{"u", "has no +, - or = operator"},
+		{"u+", "has no permissions"},
+		{"/o-", "has no permissions"},
+		{"u+x,g=", "has no permissions"},
+		{"u+x,", `clause "" has no +, - or = operator`},
+		{"u+q", "unknown permission 'q'"},
+	}
+	for _, tt := range tests {
+		_, err := ParsePermConstraint(tt.perm)
+		if err == nil {
+			t.Errorf("ParsePermConstraint(%q): expected an error", tt.perm)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParsePermConstraint(%q) error = %q, want it to contain %q", tt.perm, err, tt.want)
+		}
+	}
+}
+
+// TestPermFilterSymlink checks that a symbolic link is judged by its own bits, not its target's.
+func TestPermFilterSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target")
+	if err := os.WriteFile(target, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(target, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	broken := filepath.Join(dir, "broken")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("cannot create symbolic links: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "missing"), broken); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exact := PermConstraint{Mode: UnixMode(info.Mode()), Match: PermExact}
+
+	if PermFilter(fsys.OS, link, PermConstraint{Mode: 0o644, Match: PermExact}) && exact.Mode != 0o644 {
+		t.Error("link matched the permissions of its target")
+	}
+	for _, file := range []string{link, broken} {
+		if !PermFilter(fsys.OS, file, exact) {
+			t.Errorf("%s does not match its own permissions %04o", filepath.Base(file), exact.Mode)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package filters
+
+import (
+	"fmt"
+	"gofs/internal/fsys"
+	"gofs/utils"
+	"strings"
+)
+
+// SizeConstraint is a parsed --size argument. A bound of -1 means unbounded.
+type SizeConstraint struct {
+	Min int64
+	Max int64
+}
+
+// ParseSizeConstraint parses fd-style size constraints: "+10M" (at least), "-4k" (at most),
+// "1Mi" (exactly) and "10k..2M" (inclusive range), where either bound of a range can be left open.
+func ParseSizeConstraint(constraint string) (SizeConstraint, error) {
+	switch {
+	case strings.HasPrefix(constraint, "+"):
+		size, err := utils.ParseSize(constraint[1:])
+		if err != nil {
+			return SizeConstraint{}, err
+		}
+		return SizeConstraint{Min: size, Max: -1}, nil
+	case strings.HasPrefix(constraint, "-"):
+		size, err := utils.ParseSize(constraint[1:])
+		if err != nil {
+			return SizeConstraint{}, err
+		}
+		return SizeConstraint{Min: -1, Max: size}, nil
+	case strings.Contains(constraint, ".."):
+		lower, upper, _ := strings.Cut(constraint, "..")
+		if lower == "" && upper == "" {
+			return SizeConstraint{}, fmt.Errorf("invalid size range: %q, expected a lower or an upper bound like 10k..2M, 10k.. or ..2M", constraint)
+		}
+		min, max := int64(-1), int64(-1)
+		if lower != "" {
+			size, err := utils.ParseSize(lower)
+			if err != nil {
+				return SizeConstraint{}, fmt.Errorf("invalid size range: %q, the lower bound is invalid: %v", constraint, err)
+			}
+			min = size
+		}
+		if upper != "" {
+			size, err := utils.ParseSize(upper)
+			if err != nil {
+				return SizeConstraint{}, fmt.Errorf("invalid size range: %q, the upper bound is invalid: %v", constraint, err)
+			}
+			max = size
+		}
+		if min != -1 && max != -1 && min > max {
+			return SizeConstraint{}, fmt.Errorf("invalid size range: %q, the lower bound is larger than the upper bound", constraint)
+		}
+		return SizeConstraint{Min: min, Max: max}, nil
+	default:
+		size, err := utils.ParseSize(constraint)
+		if err != nil {
+			return SizeConstraint{}, err
+		}
+		return SizeConstraint{Min: size, Max: size}, nil
+	}
+}
+
+// SizeFilter reports whether a result is a regular file whose size satisfies every constraint.
+// Symbolic links are not followed, so they never match.
+func SizeFilter(fileSystem fsys.FS, file string, constraints []SizeConstraint) bool {
+	info, err := fileSystem.Lstat(file)
+	if err != nil || !info.Mode().IsRegular() {
+		return false // Sizes only apply to regular files
+	}
+
+	size := info.Size()
+	for _, c := range constraints {
+		if c.Min != -1 && size < c.Min {
+			return false
+		}
+		if c.Max != -1 && size > c.Max {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package filters
+
+import (
+	"gofs/internal/fsys"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSizeConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		want       SizeConstraint
+	}{
+		{"+10M", SizeConstraint{Min: 10_000_000, Max: -1}},
+		{"-4k", SizeConstraint{Min: -1, Max: 4000}},
+		{"-4Ki", SizeConstraint{Min: -1, Max: 4096}},
+		{"1Mi", SizeConstraint{Min: 1 << 20, Max: 1 << 20}},
+		{"0", SizeConstraint{Min: 0, Max: 0}},
+		{"10k..2M", SizeConstraint{Min: 10_000, Max: 2_000_000}},
+		{"1Ki..1Ki", SizeConstraint{Min: 1024, Max: 1024}},
+		{"5k..", SizeConstraint{Min: 5000, Max: -1}},
+		{"..5k", SizeConstraint{Min: -1, Max: 5000}},
+	}
+	for _, tt := range tests {
+		got, err := ParseSizeConstraint(tt.constraint)
+		if err != nil {
+			t.Errorf("ParseSizeConstraint(%q): unexpected error: %v", tt.constraint, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSizeConstraint(%q) = %+v, want %+v", tt.constraint, got, tt.want)
+		}
+	}
+}
+
+func TestParseSizeConstraintErrors(t *testing.T) {
+	tests := []struct {
+		constraint string
+		want       string // Part of the error message
+	}{
+		{"", `invalid size: ""`},
+		{"+", `invalid size: ""`},
+		{"10q", `invalid size: "10q"`},
+		{"..", "expected a lower or an upper bound"},
+		{"2M..10k", "the lower bound is larger than the upper bound"},
+		{"x..10k", "the lower bound is invalid"},
+		{"10k..x", "the upper bound is invalid"},
+		{"1k..2k..3k", "the upper bound is invalid"},
+	}
+	for _, tt := range tests {
+		_, err := ParseSizeConstraint(tt.constraint)
+		if err == nil {
+			t.Errorf("ParseSizeConstraint(%q): expected an error", tt.constraint)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseSizeConstraint(%q) error = %q, want it to contain %q", tt.constraint, err, tt.want)
+		}
+	}
+}
+
+// TestSizeFilterSymlink checks that a symbolic link is not judged by the size of its target.
+func TestSizeFilterSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target")
+	if err := os.WriteFile(target, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("cannot create symbolic links: %v", err)
+	}
+
+	constraints := []SizeConstraint{{Min: 10, Max: 10}}
+	if !SizeFilter(fsys.OS, target, constraints) {
+		t.Error("target does not match its size")
+	}
+	if SizeFilter(fsys.OS, link, constraints) {
+		t.Error("link matched the size of its target")
+	}
+}
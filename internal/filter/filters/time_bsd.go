@@ -0,0 +1,26 @@
+//go:build darwin || freebsd || netbsd
+
+package filters
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// statTime reads atime, ctime and btime from the stat result.
+func statTime(_ string, info os.FileInfo, field string) (time.Time, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, false
+	}
+	switch field {
+	case "atime":
+		return time.Unix(int64(st.Atimespec.Sec), int64(st.Atimespec.Nsec)), true
+	case "ctime":
+		return time.Unix(int64(st.Ctimespec.Sec), int64(st.Ctimespec.Nsec)), true
+	case "btime":
+		return time.Unix(int64(st.Birthtimespec.Sec), int64(st.Birthtimespec.Nsec)), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,53 @@
+package filters
+
+import (
+	"fmt"
+	"gofs/internal/fsys"
+	"time"
+)
+
+// TimeConstraint bounds a file timestamp. A zero bound means unbounded.
+type TimeConstraint struct {
+	After  time.Time
+	Before time.Time
+}
+
+// FileTime returns the given timestamp of a file: "mtime", "atime", "ctime" or "btime".
+// The field must be validated beforehand. Not every platform and file system records
+// every timestamp; an error is returned when the requested one is unavailable. Only the
+// modification time is known outside the operating system's file system. Symbolic links
+// are not followed, so a link has its own timestamps, like find's -mtime and -newer.
+func FileTime(fileSystem fsys.FS, file string, field string) (time.Time, error) {
+	info, err := fileSystem.Lstat(file)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if field == "mtime" {
+		return info.ModTime(), nil
+	}
+	if !fileSystem.Native() {
+		return time.Time{}, fmt.Errorf("%s is not available for %s outside the operating system's file system", field, file)
+	}
+	t, ok := statTime(file, info, field)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%s is not available for %s on this system", field, file)
+	}
+	return t, nil
+}
+
+// TimeFilter reports whether the chosen timestamp of a result satisfies every constraint.
+func TimeFilter(fileSystem fsys.FS, file string, field string, constraints []TimeConstraint) bool {
+	t, err := FileTime(fileSystem, file, field)
+	if err != nil {
+		return false // Skip results whose timestamp cannot be read
+	}
+	for _, c := range constraints {
+		if !c.After.IsZero() && !t.After(c.After) {
+			return false
+		}
+		if !c.Before.IsZero() && !t.Before(c.Before) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package filters
+
+import (
+	"gofs/internal/fsys"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestFileTimeSymlink checks that a symbolic link has its own timestamps, and that a broken link has them too.
+func TestFileTimeSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target")
+	if err := os.WriteFile(target, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(target, old, old); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	broken := filepath.Join(dir, "broken")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("cannot create symbolic links: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "missing"), broken); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{link, broken} {
+		got, err := FileTime(fsys.OS, file, "mtime")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", filepath.Base(file), err)
+			continue
+		}
+		if got.Equal(old) {
+			t.Errorf("%s: got the modification time of the target", filepath.Base(file))
+		}
+	}
+
+	// The link was created after 2000, so it is not older than its target's time
+	if TimeFilter(fsys.OS, link, "mtime", []TimeConstraint{{Before: old.Add(time.Hour)}}) {
+		t.Error("link matched the modification time of its target")
+	}
+}
+
+// TestFileTimeFields checks every field on the operating system's file system, and that only
+// the modification time is known elsewhere.
+func TestFileTimeFields(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	created := time.Now()
+
+	for _, field := range []string{"mtime", "atime", "ctime", "btime"} {
+		got, err := FileTime(fsys.OS, file, field)
+		if err != nil {
+			// Not every platform and file system records every timestamp
+			if field == "mtime" {
+				t.Errorf("mtime: unexpected error: %v", err)
+			}
+			continue
+		}
+		if d := created.Sub(got); d < -time.Minute || d > time.Minute {
+			t.Errorf("%s = %v, want about %v", field, got, created)
+		}
+	}
+
+	if _, err := FileTime(fsys.FromFS(os.DirFS(filepath.Dir(file))), "file", "ctime"); err == nil {
+		t.Error("ctime: expected an error outside the operating system's file system")
+	}
+}
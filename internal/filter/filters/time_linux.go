@@ -0,0 +1,39 @@
+package filters
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// statTime reads atime and ctime from the stat result and btime through statx.
+func statTime(file string, info os.FileInfo, field string) (time.Time, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, false
+	}
+	switch field {
+	case "atime":
+		return time.Unix(int64(st.Atim.Sec), int64(st.Atim.Nsec)), true
+	case "ctime":
+		return time.Unix(int64(st.Ctim.Sec), int64(st.Ctim.Nsec)), true
+	case "btime":
+		return birthTime(file)
+	}
+	return time.Time{}, false
+}
+
+// birthTime asks statx for the creation time, of a symbolic link itself rather than its target.
+// It is unavailable on kernels older than 4.11 and on file systems that do not record it.
+func birthTime(file string) (time.Time, bool) {
+	var stx unix.Statx_t
+	if err := unix.Statx(unix.AT_FDCWD, file, unix.AT_SYMLINK_NOFOLLOW, unix.STATX_BTIME, &stx); err != nil {
+		return time.Time{}, false
+	}
+	if stx.Mask&unix.STATX_BTIME == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(stx.Btime.Sec, int64(stx.Btime.Nsec)), true
+}
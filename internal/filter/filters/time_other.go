@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !windows
+
+package filters
+
+import (
+	"os"
+	"time"
+)
+
+// statTime only knows the modification time on this platform.
+func statTime(_ string, _ os.FileInfo, _ string) (time.Time, bool) {
+	return time.Time{}, false
+}
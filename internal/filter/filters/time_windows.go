@@ -0,0 +1,22 @@
+package filters
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// statTime reads atime and btime from the file attributes. Windows has no ctime.
+func statTime(_ string, info os.FileInfo, field string) (time.Time, bool) {
+	attrs, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return time.Time{}, false
+	}
+	switch field {
+	case "atime":
+		return time.Unix(0, attrs.LastAccessTime.Nanoseconds()), true
+	case "btime":
+		return time.Unix(0, attrs.CreationTime.Nanoseconds()), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,94 @@
+// Package fsys abstracts the file system a search runs on, so traversal, ignore files,
+// filters and content search work the same on the operating system and on any io/fs.FS.
+package fsys
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// FS is a file system addressed by the paths the traversal streams.
+type FS interface {
+	fs.ReadDirFS
+	fs.StatFS
+
+	// Lstat is like Stat but does not follow a final symbolic link.
+	Lstat(name string) (fs.FileInfo, error)
+
+	// ReadLink returns the target of a symbolic link.
+	ReadLink(name string) (string, error)
+
+	// Native reports whether paths are operating system paths, which can be made absolute,
+	// resolved through symbolic links and passed to system calls.
+	Native() bool
+}
+
+// OS is the operating system's file system. Paths are used as given, relative to the working directory.
+var OS FS = osFS{}
+
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error)          { return os.Open(name) }
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (osFS) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (osFS) Lstat(name string) (fs.FileInfo, error)     { return os.Lstat(name) }
+func (osFS) ReadLink(name string) (string, error)       { return os.Readlink(name) }
+func (osFS) Native() bool                               { return true }
+
+// FromFS adapts an io/fs.FS, such as os.DirFS, embed.FS, fstest.MapFS or a zip.Reader. Paths are
+// relative to its root, which is ".". Lstat falls back to Stat unless the file system has an Lstat method,
+// and ReadLink fails unless it has a ReadLink method.
+func FromFS(fsys fs.FS) FS {
+	return ioFS{fsys: fsys}
+}
+
+type ioFS struct {
+	fsys fs.FS
+}
+
+func (f ioFS) Open(name string) (fs.File, error)          { return f.fsys.Open(clean(name)) }
+func (f ioFS) ReadDir(name string) ([]fs.DirEntry, error) { return fs.ReadDir(f.fsys, clean(name)) }
+func (f ioFS) Stat(name string) (fs.FileInfo, error)      { return fs.Stat(f.fsys, clean(name)) }
+func (f ioFS) Native() bool                               { return false }
+
+func (f ioFS) Lstat(name string) (fs.FileInfo, error) {
+	if lstatFS, ok := f.fsys.(interface {
+		Lstat(name string) (fs.FileInfo, error)
+	}); ok {
+		return lstatFS.Lstat(clean(name))
+	}
+	return f.Stat(name)
+}
+
+func (f ioFS) ReadLink(name string) (string, error) {
+	if readLinkFS, ok := f.fsys.(interface {
+		ReadLink(name string) (string, error)
+	}); ok {
+		return readLinkFS.ReadLink(clean(name))
+	}
+	return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+}
+
+// clean turns a path built with path/filepath into the slash-separated form io/fs expects.
+func clean(name string) string {
+	return path.Clean(filepath.ToSlash(name))
+}
+
+// IsEmptyDir reports whether a directory has no entries, reading as few of them as possible.
+func IsEmptyDir(fsys FS, dir string) bool {
+	f, err := fsys.Open(dir)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	if d, ok := f.(fs.ReadDirFile); ok {
+		entries, err := d.ReadDir(1)
+		return len(entries) == 0 && err == io.EOF
+	}
+	entries, err := fsys.ReadDir(dir)
+	return err == nil && len(entries) == 0
+}
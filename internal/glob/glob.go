@@ -0,0 +1,190 @@
+package glob
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Options changes how a glob pattern is compiled.
+type Options struct {
+	IgnoreCase bool // Match letters regardless of case
+	NoBraces   bool // Treat "{", "}" and "," as literals, as gitignore does
+}
+
+// Glob is a compiled glob pattern.
+//
+// The syntax follows the usual shell rules with a few extensions:
+//
+//	"*"      any sequence of characters except "/"
+//	"?"      any single character except "/"
+//	"**"     as a full path segment, any number of directories ("src/**/*.go", "**/testdata/**")
+//	"[abc]"  a character class, with ranges ("[a-z]") and negation ("[!abc]" or "[^abc]")
+//	"{a,b}"  any of the comma-separated alternatives, which may be nested
+//	"\x"     the literal character x
+type Glob struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// Compile parses a glob pattern with the default options.
+func Compile(pattern string) (*Glob, error) {
+	return CompileWithOptions(pattern, Options{})
+}
+
+// CompileWithOptions parses a glob pattern into a Glob that matches whole strings.
+func CompileWithOptions(pattern string, opts Options) (*Glob, error) {
+	expr, err := Translate(pattern, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := ""
+	if opts.IgnoreCase {
+		prefix = "(?i)"
+	}
+	re, err := regexp.Compile(prefix + "^" + expr + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %q: %v", pattern, err)
+	}
+
+	return &Glob{pattern: pattern, re: re}, nil
+}
+
+// Match reports whether name matches the whole pattern.
+func (g *Glob) Match(name string) bool {
+	return g.re.MatchString(name)
+}
+
+// String returns the source pattern.
+func (g *Glob) String() string {
+	return g.pattern
+}
+
+// Translate converts a glob pattern into an unanchored regular expression.
+// The IgnoreCase option is not applied; callers add the flag to the final expression.
+func Translate(pattern string, opts Options) (string, error) {
+	if pattern == "" {
+		return "", errors.New("empty glob pattern")
+	}
+
+	var sb strings.Builder
+	braceDepth := 0
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' && isSegmentStart(pattern, i, opts) && isSegmentEnd(pattern, i+2, opts) {
+				if i+2 < len(pattern) && pattern[i+2] == '/' {
+					// "**/" matches zero or more directories
+					sb.WriteString("(?:.*/)?")
+					i += 2
+				} else {
+					// A trailing "**" matches everything below
+					sb.WriteString(".*")
+					i++
+				}
+				continue
+			}
+			// Other consecutive asterisks behave like a single "*"
+			for i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+			}
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			class, next, err := translateClass(pattern, i)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(class)
+			i = next
+		case c == '\\':
+			if i+1 == len(pattern) {
+				return "", fmt.Errorf("invalid glob pattern %q: trailing backslash", pattern)
+			}
+			i = writeLiteral(&sb, pattern, i+1)
+		case c == '{' && !opts.NoBraces:
+			braceDepth++
+			sb.WriteString("(?:")
+		case c == ',' && !opts.NoBraces && braceDepth > 0:
+			sb.WriteString("|")
+		case c == '}' && !opts.NoBraces && braceDepth > 0:
+			braceDepth--
+			sb.WriteString(")")
+		default:
+			i = writeLiteral(&sb, pattern, i)
+		}
+	}
+
+	if braceDepth > 0 {
+		return "", fmt.Errorf("invalid glob pattern %q: unclosed brace", pattern)
+	}
+
+	return sb.String(), nil
+}
+
+// writeLiteral writes the whole character starting at pattern[i] as a literal and returns the
+// index of its last byte, so characters outside ASCII are not split into bytes.
+func writeLiteral(sb *strings.Builder, pattern string, i int) int {
+	_, size := utf8.DecodeRuneInString(pattern[i:])
+	sb.WriteString(regexp.QuoteMeta(pattern[i : i+size]))
+	return i + size - 1
+}
+
+// isSegmentStart reports whether pattern[i] starts a path segment.
+func isSegmentStart(pattern string, i int, opts Options) bool {
+	if i == 0 || pattern[i-1] == '/' {
+		return true
+	}
+	return !opts.NoBraces && (pattern[i-1] == '{' || pattern[i-1] == ',')
+}
+
+// isSegmentEnd reports whether a path segment ends right before pattern[i].
+func isSegmentEnd(pattern string, i int, opts Options) bool {
+	if i == len(pattern) || pattern[i] == '/' {
+		return true
+	}
+	return !opts.NoBraces && (pattern[i] == '}' || pattern[i] == ',')
+}
+
+// translateClass converts a bracket expression starting at pattern[start] into a regex class.
+// It returns the class and the index of the closing bracket.
+func translateClass(pattern string, start int) (string, int, error) {
+	var sb strings.Builder
+	sb.WriteString("[")
+
+	i := start + 1
+	if i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^') {
+		sb.WriteString("^/") // Negated classes never match the separator
+		i++
+	}
+
+	// A "]" right after the opening bracket is a literal
+	first := true
+	for ; i < len(pattern); i++ {
+		c := pattern[i]
+		if c == ']' && !first {
+			sb.WriteString("]")
+			return sb.String(), i, nil
+		}
+		first = false
+
+		switch c {
+		case '\\':
+			if i+1 < len(pattern) {
+				i = writeLiteral(&sb, pattern, i+1)
+			}
+		case '[', ']', '^':
+			sb.WriteString(`\` + string(c))
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return "", start, fmt.Errorf("invalid glob pattern %q: unclosed character class", pattern)
+}
@@ -0,0 +1,95 @@
+package glob
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		opts    Options
+		name    string
+		want    bool
+	}{
+		// Wildcards
+		{"*.go", Options{}, "main.go", true},
+		{"*.go", Options{}, "cmd/main.go", false},
+		{"*.go", Options{}, ".go", true},
+		{"?.go", Options{}, "a.go", true},
+		{"?.go", Options{}, "ab.go", false},
+		{"?", Options{}, "/", false},
+		{"a***b", Options{}, "aXYb", true},
+
+		// Double asterisks
+		{"**/*.go", Options{}, "main.go", true},
+		{"**/*.go", Options{}, "a/b/c/main.go", true},
+		{"src/**/*.go", Options{}, "src/main.go", true},
+		{"src/**/*.go", Options{}, "src/a/b/main.go", true},
+		{"src/**/*.go", Options{}, "lib/src/main.go", false},
+		{"**/testdata/**", Options{}, "pkg/testdata/x/y.txt", true},
+		{"src/**", Options{}, "src/a/b", true},
+		{"a**b", Options{}, "a/b", false}, // Not a full segment, so a single "*"
+		{"a**b", Options{}, "axyb", true},
+
+		// Braces
+		{"*.{go,rs}", Options{}, "main.rs", true},
+		{"*.{go,rs}", Options{}, "main.py", false},
+		{"{src,lib}/**/*.{js,ts}", Options{}, "lib/x/index.ts", true},
+		{"a{b,c{d,e}}f", Options{}, "acef", true},
+		{"a{b,c{d,e}}f", Options{}, "acf", false},
+		{"{**/,}*.go", Options{}, "a/b.go", true},
+		{"*.{go,rs}", Options{NoBraces: true}, "main.go", false},
+		{"*.{go,rs}", Options{NoBraces: true}, "main.{go,rs}", true},
+		{"a,b", Options{}, "a,b", true},
+
+		// Character classes
+		{"[abc].txt", Options{}, "b.txt", true},
+		{"[abc].txt", Options{}, "d.txt", false},
+		{"[a-c].txt", Options{}, "c.txt", true},
+		{"[!a-c].txt", Options{}, "d.txt", true},
+		{"[^a-c].txt", Options{}, "a.txt", false},
+		{"[!a]", Options{}, "/", false},
+		{"[]a]", Options{}, "]", true},
+		{"[[]", Options{}, "[", true},
+		{`[\]]`, Options{}, "]", true},
+
+		// Escapes
+		{`\*.go`, Options{}, "*.go", true},
+		{`\*.go`, Options{}, "main.go", false},
+		{`\{a,b\}`, Options{}, "{a,b}", true},
+		{`a\?`, Options{}, "a?", true},
+		{"a.b", Options{}, "axb", false},
+		{"a+(b)", Options{}, "a+(b)", true},
+
+		// Characters outside ASCII
+		{"café.txt", Options{}, "café.txt", true},
+		{"café*", Options{}, "café au lait", true},
+		{"résumé.pdf", Options{}, "resume.pdf", false},
+		{"?afé", Options{}, "éafé", true},
+		{"[éè]t[éè]", Options{}, "été", true},
+		{`caf\é`, Options{}, "café", true},
+		{"日本/*.txt", Options{}, "日本/語.txt", true},
+		{"CAFÉ.TXT", Options{IgnoreCase: true}, "café.txt", true},
+
+		// Case
+		{"*.GO", Options{}, "main.go", false},
+		{"*.GO", Options{IgnoreCase: true}, "main.go", true},
+	}
+
+	for _, tt := range tests {
+		g, err := CompileWithOptions(tt.pattern, tt.opts)
+		if err != nil {
+			t.Errorf("CompileWithOptions(%q, %+v): unexpected error: %v", tt.pattern, tt.opts, err)
+			continue
+		}
+		if got := g.Match(tt.name); got != tt.want {
+			t.Errorf("%q (%+v).Match(%q) = %v, want %v", tt.pattern, tt.opts, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, pattern := range []string{"", "[abc", "{a,b", `abc\`} {
+		if _, err := Compile(pattern); err == nil {
+			t.Errorf("Compile(%q): expected an error", pattern)
+		}
+	}
+}
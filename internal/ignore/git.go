@@ -0,0 +1,176 @@
+package ignore
+
+import (
+	"bufio"
+	"gofs/internal/fsys"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// repository holds the repository-wide git ignore rules.
+type repository struct {
+	top      string
+	matchers []*Matcher // info/exclude first, then core.excludesFile
+}
+
+// openRepository loads .git/info/exclude and core.excludesFile for the repository at top.
+func openRepository(fileSystem fsys.FS, top string) *repository {
+	repo := &repository{top: top}
+	gitDir := resolveGitDir(fileSystem, filepath.Join(top, gitDirName))
+
+	if m, err := ParseFile(fileSystem, filepath.Join(gitDir, "info", "exclude"), top, true); err == nil {
+		repo.matchers = append(repo.matchers, m)
+	}
+
+	// core.excludesFile names a file on the operating system, so it only applies to repositories there
+	if fileSystem.Native() {
+		if path := excludesFile(gitDir); path != "" {
+			if m, err := ParseFile(fsys.OS, path, top, true); err == nil {
+				repo.matchers = append(repo.matchers, m)
+			}
+		}
+	}
+
+	return repo
+}
+
+// findRepositoryTop walks up from dir to the nearest directory containing a .git entry.
+func findRepositoryTop(fileSystem fsys.FS, dir string) (string, bool) {
+	for {
+		if _, err := fileSystem.Stat(filepath.Join(dir, gitDirName)); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// resolveGitDir follows the "gitdir:" indirection used by worktrees and submodules.
+func resolveGitDir(fileSystem fsys.FS, dotGit string) string {
+	info, err := fileSystem.Stat(dotGit)
+	if err != nil || info.IsDir() {
+		return dotGit
+	}
+
+	data, err := fs.ReadFile(fileSystem, dotGit)
+	if err != nil {
+		return dotGit
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return dotGit
+	}
+	target = strings.TrimSpace(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(dotGit), target)
+	}
+	return target
+}
+
+// excludesFile returns core.excludesFile for a repository. The repository's own config
+// overrides the user and system config, which fall back to git's default location.
+func excludesFile(gitDir string) string {
+	if path, ok := configExcludesFile(filepath.Join(gitDir, "config")); ok {
+		return path
+	}
+	return globalExcludesFile()
+}
+
+// globalExcludesFile resolves core.excludesFile from the user and system git config once.
+var globalExcludesFile = sync.OnceValue(func() string {
+	home, _ := os.UserHomeDir()
+	xdgConfig := os.Getenv("XDG_CONFIG_HOME")
+	if xdgConfig == "" && home != "" {
+		xdgConfig = filepath.Join(home, ".config")
+	}
+
+	// Highest precedence first: ~/.gitconfig, then the XDG config, then the system config
+	var configs []string
+	if home != "" {
+		configs = append(configs, filepath.Join(home, ".gitconfig"))
+	}
+	if xdgConfig != "" {
+		configs = append(configs, filepath.Join(xdgConfig, "git", "config"))
+	}
+	configs = append(configs, "/etc/gitconfig")
+
+	for _, config := range configs {
+		if path, ok := configExcludesFile(config); ok {
+			return path
+		}
+	}
+
+	if xdgConfig != "" {
+		return filepath.Join(xdgConfig, "git", "ignore")
+	}
+	return ""
+})
+
+// configExcludesFile reads core.excludesFile from a git config file, if it is set there.
+func configExcludesFile(config string) (string, bool) {
+	file, err := os.Open(config)
+	if err != nil {
+		return "", false
+	}
+	defer file.Close()
+
+	var (
+		section string
+		value   string
+		found   bool
+	)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		// Section headers like [core] or [remote "origin"]
+		if line[0] == '[' {
+			name := strings.Trim(line, "[]")
+			if i := strings.IndexAny(name, " \t"); i != -1 {
+				name = name[:i]
+			}
+			section = strings.ToLower(name)
+			continue
+		}
+
+		if section != "core" {
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(key), "excludesfile") {
+			continue
+		}
+
+		// The last assignment wins
+		value = strings.Trim(strings.TrimSpace(val), `"`)
+		found = true
+	}
+
+	if !found {
+		return "", false
+	}
+	return expandHome(value), true
+}
+
+// expandHome expands a leading "~/" to the user's home directory.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
@@ -0,0 +1,80 @@
+package ignore
+
+import (
+	"bufio"
+	"gofs/internal/fsys"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Matcher holds the rules of a single ignore file, relative to the directory they apply to.
+type Matcher struct {
+	base  string // Directory the patterns are relative to
+	rules []rule
+	vcs   bool // Rules come from git (.gitignore, info/exclude, core.excludesFile)
+}
+
+// NewMatcher compiles ignore rules read from r. Patterns are matched relative to base.
+func NewMatcher(r io.Reader, base string, vcs bool) (*Matcher, error) {
+	m := &Matcher{base: filepath.Clean(base), vcs: vcs}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if rule, ok := parseRule(scanner.Text()); ok {
+			m.rules = append(m.rules, rule)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// ParseFile reads an ignore file from fileSystem whose patterns are matched relative to base.
+func ParseFile(fileSystem fsys.FS, path string, base string, vcs bool) (*Matcher, error) {
+	file, err := fileSystem.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return NewMatcher(file, base, vcs)
+}
+
+// Match checks a path against the rules. Following git, the last matching rule wins.
+// It returns whether the path is ignored and whether any rule matched at all.
+func (m *Matcher) Match(path string, isDir bool) (ignored bool, matched bool) {
+	rel, ok := relativeTo(m.base, path)
+	if !ok {
+		return false, false
+	}
+
+	for i := len(m.rules) - 1; i >= 0; i-- {
+		if m.rules[i].match(rel, isDir) {
+			return !m.rules[i].negate, true
+		}
+	}
+	return false, false
+}
+
+// relativeTo returns path relative to base using forward slashes, if path is below base.
+func relativeTo(base, path string) (string, bool) {
+	// The root of a non-native file system contains every relative path
+	if base == "." {
+		if filepath.IsAbs(path) || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+			return "", false
+		}
+		return filepath.ToSlash(path), true
+	}
+
+	prefix := base
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	if !strings.HasPrefix(path, prefix) {
+		return "", false
+	}
+	return filepath.ToSlash(path[len(prefix):]), true
+}
@@ -0,0 +1,89 @@
+package ignore
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatcher(t *testing.T) {
+	tests := []struct {
+		rules   string
+		path    string
+		isDir   bool
+		ignored bool
+		matched bool
+	}{
+		// Basic patterns match at any level
+		{"*.log", "debug.log", false, true, true},
+		{"*.log", "a/b/debug.log", false, true, true},
+		{"*.log", "debug.txt", false, false, false},
+		{"tmp", "a/tmp", true, true, true},
+
+		// Negation: the last matching rule wins
+		{"*.log\n!keep.log", "keep.log", false, false, true},
+		{"*.log\n!keep.log", "other.log", false, true, true},
+		{"!keep.log\n*.log", "keep.log", false, true, true},
+		{`\!important`, "!important", false, true, true},
+		{`\#notes`, "#notes", false, true, true},
+		{"# comment\n\n", "# comment", false, false, false},
+
+		// A leading or middle slash anchors the pattern to the ignore file's directory
+		{"/build", "build", true, true, true},
+		{"/build", "src/build", true, false, false},
+		{"docs/*.md", "docs/a.md", false, true, true},
+		{"docs/*.md", "x/docs/a.md", false, false, false},
+		{"docs/*.md", "docs/sub/a.md", false, false, false},
+		{"**/docs/*.md", "x/docs/a.md", false, true, true},
+		{"src/**", "src/a/b.go", false, true, true},
+
+		// A trailing slash only matches directories
+		{"out/", "out", true, true, true},
+		{"out/", "out", false, false, false},
+
+		// Trailing spaces are trimmed unless escaped, braces are literal
+		{"a.txt   ", "a.txt", false, true, true},
+		{`a\ `, "a ", false, true, true},
+		{"*.{go,rs}", "main.go", false, false, false},
+		{"*.{go,rs}", "main.{go,rs}", false, true, true},
+
+		// Characters outside ASCII
+		{"résumé.pdf", "résumé.pdf", false, true, true},
+		{"résumé.pdf", "docs/résumé.pdf", false, true, true},
+		{"/日本/*.txt", "日本/語.txt", false, true, true},
+	}
+
+	for _, tt := range tests {
+		m, err := NewMatcher(strings.NewReader(tt.rules), ".", true)
+		if err != nil {
+			t.Fatalf("NewMatcher(%q): unexpected error: %v", tt.rules, err)
+		}
+		ignored, matched := m.Match(filepath.FromSlash(tt.path), tt.isDir)
+		if ignored != tt.ignored || matched != tt.matched {
+			t.Errorf("rules %q: Match(%q, %v) = (%v, %v), want (%v, %v)", tt.rules, tt.path, tt.isDir, ignored, matched, tt.ignored, tt.matched)
+		}
+	}
+}
+
+func TestMatcherBase(t *testing.T) {
+	base := filepath.FromSlash("/repo/sub")
+	m, err := NewMatcher(strings.NewReader("/a.txt"), base, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		ignored bool
+	}{
+		{"/repo/sub/a.txt", true},
+		{"/repo/a.txt", false},        // Outside the base
+		{"/repo/subway/a.txt", false}, // A sibling sharing the base's prefix
+		{"/repo/sub/x/a.txt", false},  // Anchored to the base
+	}
+	for _, tt := range tests {
+		if ignored, _ := m.Match(filepath.FromSlash(tt.path), false); ignored != tt.ignored {
+			t.Errorf("Match(%q) = %v, want %v", tt.path, ignored, tt.ignored)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package ignore
+
+import (
+	"gofs/internal/glob"
+	"regexp"
+	"strings"
+)
+
+// rule is a single compiled line of an ignore file.
+type rule struct {
+	re      *regexp.Regexp // Matches the path relative to the ignore file's directory
+	negate  bool           // Pattern started with "!" and re-includes matching paths
+	dirOnly bool           // Pattern ended with "/" and only matches directories
+}
+
+// parseRule compiles a single gitignore line. It returns false for blank lines and comments.
+func parseRule(line string) (rule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	line = trimTrailingSpaces(line)
+
+	// Skip comments and empty lines
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false
+	}
+
+	var r rule
+
+	// A leading "!" negates the pattern, "\!" and "\#" are literals
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	// A trailing "/" only matches directories
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule{}, false
+	}
+
+	// A "/" at the beginning or in the middle anchors the pattern to the ignore file's directory,
+	// otherwise it matches at any level below it
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	// Gitignore patterns have no brace expansion
+	expr, err := glob.Translate(line, glob.Options{NoBraces: true})
+	if err != nil {
+		return rule{}, false // Invalid patterns are ignored, like git does
+	}
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return rule{}, false // Invalid patterns are ignored, like git does
+	}
+	r.re = re
+
+	return r, true
+}
+
+// match checks the rule against a slash-separated relative path.
+func (r rule) match(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	return r.re.MatchString(rel)
+}
+
+// trimTrailingSpaces removes trailing spaces unless they are escaped with a backslash.
+func trimTrailingSpaces(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	return line
+}
@@ -0,0 +1,175 @@
+package ignore
+
+import (
+	"gofs/internal/fsys"
+	"io/fs"
+	"path/filepath"
+)
+
+// Names of the files that shape the ignore rules of a directory
+const (
+	gitDirName        = ".git"
+	gitIgnoreFileName = ".gitignore"
+	ignoreFileName    = ".ignore"
+)
+
+// Options selects which ignore rules are respected during traversal.
+type Options struct {
+	NoIgnore    bool     // Skip .gitignore, .ignore and the repository-wide git rules
+	NoIgnoreVCS bool     // Skip .gitignore, .git/info/exclude and core.excludesFile
+	Files       []string // Custom ignore files, matched relative to each search root
+}
+
+// Enabled reports whether any ignore rules have to be evaluated.
+func (o Options) Enabled() bool {
+	return !o.NoIgnore || len(o.Files) > 0
+}
+
+// Stack is the set of ignore rules in effect for one directory during traversal.
+// A directory with its own ignore files adds a layer on top of its parent's stack,
+// so deeper ignore files take precedence over the ones above them, as in git.
+type Stack struct {
+	parent   *Stack
+	matchers []*Matcher  // Ignore files of this directory, lowest precedence first
+	repo     *repository // Git repository the directory belongs to, if any
+	custom   []*Matcher  // Custom ignore files, only set on the bottom layer
+	options  *Options
+	fsys     fsys.FS // File system the ignore files are read from
+}
+
+// NewRootStack builds the stack in effect above a search root. When the root is inside a
+// git repository, the ignore files from the repository's top level down to the root's parent
+// are loaded, so the results match what git sees. The root's own ignore files are picked up
+// by Child when the root directory is read. Custom ignore files are anchored at the root and
+// have the lowest precedence, and are always read from the operating system.
+func NewRootStack(fileSystem fsys.FS, root string, options Options) *Stack {
+	stack := &Stack{options: &options, fsys: fileSystem}
+
+	for _, file := range options.Files {
+		if m, err := ParseFile(fsys.OS, file, root, false); err == nil {
+			stack.custom = append(stack.custom, m)
+		}
+	}
+
+	if options.NoIgnore {
+		return stack
+	}
+
+	top, ok := findRepositoryTop(fileSystem, filepath.Dir(root))
+	if !ok {
+		return stack
+	}
+
+	// Collect the directories from the repository's top level down to the root's parent
+	var dirs []string
+	for dir := filepath.Dir(root); ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if dir == top {
+			break
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		dir := dirs[i]
+		stack = stack.push(dir, dir == top, fileExists(fileSystem, filepath.Join(dir, gitIgnoreFileName)), fileExists(fileSystem, filepath.Join(dir, ignoreFileName)))
+	}
+
+	return stack
+}
+
+// Child returns the stack in effect for the entries of dir, given the entries read from it.
+func (s *Stack) Child(dir string, entries []fs.DirEntry) *Stack {
+	var hasGit, hasGitIgnore, hasIgnore bool
+	for _, entry := range entries {
+		switch entry.Name() {
+		case gitDirName:
+			hasGit = true
+		case gitIgnoreFileName:
+			hasGitIgnore = true
+		case ignoreFileName:
+			hasIgnore = true
+		}
+	}
+	return s.push(dir, hasGit, hasGitIgnore, hasIgnore)
+}
+
+// push adds a layer for dir if it starts a repository or has ignore files of its own.
+func (s *Stack) push(dir string, hasGit, hasGitIgnore, hasIgnore bool) *Stack {
+	if s.options.NoIgnore {
+		return s
+	}
+	if s.options.NoIgnoreVCS {
+		hasGit, hasGitIgnore = false, false
+	}
+
+	repo := s.repo
+	if hasGit {
+		repo = openRepository(s.fsys, dir) // A nested repository starts over with its own git rules
+	}
+	if !hasGitIgnore && !hasIgnore && repo == s.repo {
+		return s
+	}
+
+	child := &Stack{parent: s, repo: repo, options: s.options, fsys: s.fsys}
+
+	// .gitignore only applies inside a git repository
+	if hasGitIgnore && repo != nil {
+		if m, err := ParseFile(s.fsys, filepath.Join(dir, gitIgnoreFileName), dir, true); err == nil {
+			child.matchers = append(child.matchers, m)
+		}
+	}
+
+	// .ignore takes precedence over .gitignore in the same directory
+	if hasIgnore {
+		if m, err := ParseFile(s.fsys, filepath.Join(dir, ignoreFileName), dir, false); err == nil {
+			child.matchers = append(child.matchers, m)
+		}
+	}
+
+	return child
+}
+
+// Ignored reports whether path should be skipped. Rules are checked from the deepest
+// ignore file upwards, then the repository's info/exclude and core.excludesFile, and
+// finally the custom ignore files. The first ignore file with a matching rule decides.
+func (s *Stack) Ignored(path string, isDir bool) bool {
+	bottom := s
+	for layer := s; layer != nil; layer = layer.parent {
+		bottom = layer
+
+		for i := len(layer.matchers) - 1; i >= 0; i-- {
+			m := layer.matchers[i]
+
+			// Git rules of an enclosing repository do not reach into a nested one
+			if m.vcs && layer.repo != s.repo {
+				continue
+			}
+
+			if ignored, matched := m.Match(path, isDir); matched {
+				return ignored
+			}
+		}
+	}
+
+	if s.repo != nil {
+		for _, m := range s.repo.matchers {
+			if ignored, matched := m.Match(path, isDir); matched {
+				return ignored
+			}
+		}
+	}
+
+	for i := len(bottom.custom) - 1; i >= 0; i-- {
+		if ignored, matched := bottom.custom[i].Match(path, isDir); matched {
+			return ignored
+		}
+	}
+
+	return false
+}
+
+// fileExists reports whether a regular file exists at path.
+func fileExists(fileSystem fsys.FS, path string) bool {
+	info, err := fileSystem.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
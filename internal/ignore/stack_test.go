@@ -0,0 +1,142 @@
+package ignore
+
+import (
+	"gofs/internal/fsys"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+// ignoredPaths walks root like the traversal does and returns the paths the stacks ignore,
+// without descending into ignored directories.
+func ignoredPaths(t *testing.T, fileSystem fsys.FS, root string, options Options) []string {
+	t.Helper()
+
+	var ignored []string
+	var walk func(dir string, stack *Stack)
+	walk = func(dir string, stack *Stack) {
+		entries, err := fileSystem.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stack = stack.Child(dir, entries)
+		for _, entry := range entries {
+			p := path.Join(dir, entry.Name())
+			if entry.Name() == gitDirName {
+				continue
+			}
+			if stack.Ignored(p, entry.IsDir()) {
+				ignored = append(ignored, p)
+				continue
+			}
+			if entry.IsDir() {
+				walk(p, stack)
+			}
+		}
+	}
+	walk(root, NewRootStack(fileSystem, root, options))
+
+	slices.Sort(ignored)
+	return ignored
+}
+
+func TestStack(t *testing.T) {
+	files := fstest.MapFS{
+		"repo/.git/info/exclude":        {Data: []byte("*.bak\n")},
+		"repo/.gitignore":               {Data: []byte("*.log\n/build/\nsecret.txt\n")},
+		"repo/.ignore":                  {Data: []byte("!secret.txt\n")},
+		"repo/a.log":                    {},
+		"repo/a.bak":                    {},
+		"repo/secret.txt":               {},
+		"repo/build/out":                {},
+		"repo/src/build/keep.go":        {},
+		"repo/src/.gitignore":           {Data: []byte("!important.log\n")},
+		"repo/src/important.log":        {},
+		"repo/src/other.log":            {},
+		"repo/nested/.git/info/exclude": {Data: []byte("")},
+		"repo/nested/a.log":             {},
+		"repo/nested/.ignore":           {Data: []byte("*.tmp\n")},
+		"repo/nested/b.tmp":             {},
+		"plain/.gitignore":              {Data: []byte("*\n")},
+		"plain/.ignore":                 {Data: []byte("*.tmp\n")},
+		"plain/a.txt":                   {},
+		"plain/b.tmp":                   {},
+	}
+	fileSystem := fsys.FromFS(files)
+
+	tests := []struct {
+		name    string
+		root    string
+		options Options
+		want    []string
+	}{
+		{
+			name: "repository",
+			root: "repo",
+			want: []string{
+				"repo/a.bak",         // info/exclude
+				"repo/a.log",         // .gitignore
+				"repo/build",         // Anchored directory pattern
+				"repo/src/other.log", // Inherited from the parent .gitignore
+				// repo/secret.txt is re-included by .ignore, which beats .gitignore in the same directory
+				// repo/src/build is not the anchored /build
+				// repo/src/important.log is re-included by the deeper .gitignore
+				// repo/nested/a.log is in a nested repository, out of reach of the outer .gitignore
+				"repo/nested/b.tmp", // .ignore of the nested repository
+			},
+		},
+		{
+			name: "outside a repository",
+			root: "plain",
+			want: []string{"plain/b.tmp"}, // .gitignore only applies inside a git repository
+		},
+		{
+			name:    "no-ignore-vcs",
+			root:    "repo",
+			options: Options{NoIgnoreVCS: true},
+			want:    []string{"repo/nested/b.tmp"},
+		},
+		{
+			name:    "no-ignore",
+			root:    "repo",
+			options: Options{NoIgnore: true},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ignoredPaths(t, fileSystem, tt.root, tt.options)
+			slices.Sort(tt.want)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ignored %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStackCustomFiles(t *testing.T) {
+	// Custom ignore files are read from the operating system and have the lowest precedence
+	custom := filepath.Join(t.TempDir(), "custom-ignore")
+	if err := os.WriteFile(custom, []byte("*.txt\n/top.md\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fileSystem := fsys.FromFS(fstest.MapFS{
+		"repo/.git/info/exclude": {Data: []byte("")},
+		"repo/.gitignore":        {Data: []byte("!keep.txt\n")},
+		"repo/a.txt":             {},
+		"repo/keep.txt":          {},
+		"repo/top.md":            {},
+		"repo/sub/top.md":        {},
+	})
+
+	got := ignoredPaths(t, fileSystem, "repo", Options{Files: []string{custom}})
+	want := []string{"repo/a.txt", "repo/top.md"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ignored %q, want %q", got, want)
+	}
+}
@@ -1,26 +1,23 @@
 package formats
 
 import (
-	"os"
+	"gofs/internal/traverse"
 	"path/filepath"
 )
 
-func AbsPathFormat(results []string) []string {
-	var absolutePaths []string
-	for _, file := range results {
-		absPath, err := filepath.Abs(file)
-		if err != nil {
-			continue
-		} else {
-			info, err := os.Stat(file)
-			if err != nil {
-				continue
-			}
-			if info.IsDir() {
-				absPath += string(filepath.Separator)
-			}
-			absolutePaths = append(absolutePaths, absPath)
-		}
+// AbsPathFormat converts the displayed path of a result to its absolute path. Directories keep a
+// trailing separator. Like fs.DirEntry.Info, it looks at symbolic links themselves, so broken links are kept.
+func AbsPathFormat(entry traverse.Entry, file string) (string, bool) {
+	absPath, err := filepath.Abs(file)
+	if err != nil {
+		return "", false
 	}
-	return absolutePaths
+	info, err := entry.Info()
+	if err != nil {
+		return "", false
+	}
+	if info.IsDir() {
+		absPath += string(filepath.Separator)
+	}
+	return absPath, true
 }
@@ -0,0 +1,34 @@
+package formats
+
+import (
+	"gofs/internal/fsys"
+	"gofs/internal/traverse"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBrokenSymlink checks that the formatters describe broken links instead of dropping them.
+func TestBrokenSymlink(t *testing.T) {
+	dir := t.TempDir()
+	link := filepath.Join(dir, "broken")
+	if err := os.Symlink(filepath.Join(dir, "missing"), link); err != nil {
+		t.Skipf("cannot create symbolic links: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := traverse.NewEntry(fsys.OS, link, dir, entries[0])
+
+	if got, ok := AbsPathFormat(entry, link); !ok || got != link {
+		t.Errorf("AbsPathFormat = %q, %v, want %q", got, ok, link)
+	}
+	if got, ok := LongListFormat(entry); !ok || !strings.HasPrefix(got, "L ") {
+		t.Errorf("LongListFormat = %q, %v, want the columns of the link itself", got, ok)
+	}
+	if record, ok := JSONRecord(entry); !ok || record.Type != "symlink" {
+		t.Errorf("JSONRecord = %+v, %v, want a symlink record", record, ok)
+	}
+}
@@ -0,0 +1,40 @@
+package formats
+
+import (
+	"gofs/internal/fsys"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// hostname is looked up once, as every link carries it.
+var hostname = sync.OnceValue(func() string {
+	name, _ := os.Hostname()
+	return name
+})
+
+// HyperlinkFormat returns the file:// URL of a result, with the host name so terminals can tell
+// local files from files on another machine reached over ssh. Only files of the operating system's
+// file system can be linked; members of archives found with --search-archives cannot.
+func HyperlinkFormat(fileSystem fsys.FS, file string) (string, bool) {
+	if !fileSystem.Native() {
+		return "", false
+	}
+	absPath, err := filepath.Abs(file)
+	if err != nil {
+		return "", false
+	}
+	if _, err := os.Lstat(absPath); err != nil {
+		return "", false // Archive members only exist inside their archive
+	}
+
+	// Windows paths like C:\dir become /C:/dir
+	urlPath := filepath.ToSlash(absPath)
+	if !strings.HasPrefix(urlPath, "/") {
+		urlPath = "/" + urlPath
+	}
+	link := url.URL{Scheme: "file", Host: hostname(), Path: urlPath}
+	return link.String(), true
+}
@@ -0,0 +1,49 @@
+package formats
+
+import (
+	"gofs/internal/fsys"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestHyperlinkFormat(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		want string // Escaped name at the end of the URL
+	}{
+		{"plain.txt", "plain.txt"},
+		{"with space.txt", "with%20space.txt"},
+		{"#1.txt", "%231.txt"},
+		{"why?.txt", "why%3F.txt"},
+		{"100%.txt", "100%25.txt"},
+		{"a+b,c=d.txt", "a+b,c=d.txt"},
+		{"café.txt", "caf%C3%A9.txt"},
+	}
+	for _, tt := range tests {
+		file := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(file, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		want := "file://" + hostname() + filepath.ToSlash(dir) + "/" + tt.want
+		got, ok := HyperlinkFormat(fsys.OS, file)
+		if !ok || got != want {
+			t.Errorf("HyperlinkFormat(%q) = %q, %v, want %q, true", tt.name, got, ok, want)
+		}
+	}
+}
+
+// TestHyperlinkFormatUnlinkable checks that files outside the operating system's file system get no link.
+func TestHyperlinkFormatUnlinkable(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "release.tar.gz!", "bin", "app")
+	if got, ok := HyperlinkFormat(fsys.OS, missing); ok {
+		t.Errorf("HyperlinkFormat(%q) = %q, want no link", missing, got)
+	}
+
+	mapFS := fsys.FromFS(fstest.MapFS{"file.txt": {}})
+	if got, ok := HyperlinkFormat(mapFS, "file.txt"); ok {
+		t.Errorf("HyperlinkFormat on a non-native file system = %q, want no link", got)
+	}
+}
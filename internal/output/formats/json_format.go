@@ -0,0 +1,94 @@
+package formats
+
+import (
+	"fmt"
+	"gofs/internal/filter/filters"
+	"gofs/internal/traverse"
+	"io/fs"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Record describes a result for the JSON output formats.
+type Record struct {
+	Path          string    `json:"path"`
+	AbsolutePath  string    `json:"absolute_path,omitempty"` // Empty for file systems other than the operating system's
+	Type          string    `json:"type"`                    // As named by --file-type, e.g. "file" or "symlink"
+	Size          int64     `json:"size"`
+	Mode          string    `json:"mode"` // Permission bits in octal, e.g. "0644"
+	ModTime       time.Time `json:"mtime"`
+	Owner         string    `json:"owner,omitempty"` // User name, or the numeric ID when it has no name
+	Group         string    `json:"group,omitempty"` // Group name, or the numeric ID when it has no name
+	SymlinkTarget string    `json:"symlink_target,omitempty"`
+	Matches       []Match   `json:"matches,omitempty"` // Lines matched by a content search
+}
+
+// Match is a line matched by a content search, or a context line around a match.
+type Match struct {
+	Line    int    `json:"line"`
+	Text    string `json:"text"`
+	Context bool   `json:"context,omitempty"`
+}
+
+// JSONRecord describes a result. Like fs.DirEntry.Info, it describes a symbolic link itself rather than its target.
+func JSONRecord(entry traverse.Entry) (Record, bool) {
+	info, err := entry.Info()
+	if err != nil {
+		return Record{}, false
+	}
+
+	record := Record{
+		Path:    entry.Path,
+		Type:    filters.FileTypeName(info.Mode()),
+		Size:    info.Size(),
+		Mode:    fmt.Sprintf("%04o", filters.UnixMode(info.Mode())),
+		ModTime: info.ModTime(),
+	}
+	if entry.FS.Native() {
+		if absPath, err := filepath.Abs(entry.Path); err == nil {
+			record.AbsolutePath = absPath
+		}
+	}
+	if uid, gid, ok := filters.FileOwner(info); ok {
+		record.Owner = lookupName(&userNames, uid, func(id string) (string, error) {
+			u, err := user.LookupId(id)
+			if err != nil {
+				return "", err
+			}
+			return u.Username, nil
+		})
+		record.Group = lookupName(&groupNames, gid, func(id string) (string, error) {
+			g, err := user.LookupGroupId(id)
+			if err != nil {
+				return "", err
+			}
+			return g.Name, nil
+		})
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		record.SymlinkTarget, _ = entry.FS.ReadLink(entry.Path)
+	}
+	for _, match := range entry.Matches {
+		record.Matches = append(record.Matches, Match{Line: match.Number, Text: match.Text, Context: match.Context})
+	}
+	return record, true
+}
+
+// Names of the user and group IDs seen so far, as most results share a handful of owners
+var userNames, groupNames sync.Map
+
+// lookupName resolves an ID to a name once, falling back to the ID itself.
+func lookupName(names *sync.Map, id uint32, lookup func(string) (string, error)) string {
+	if name, ok := names.Load(id); ok {
+		return name.(string)
+	}
+	name, err := lookup(strconv.FormatUint(uint64(id), 10))
+	if err != nil {
+		name = strconv.FormatUint(uint64(id), 10)
+	}
+	names.Store(id, name)
+	return name
+}
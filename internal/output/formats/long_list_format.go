@@ -2,22 +2,20 @@ package formats
 
 import (
 	"fmt"
-	"os"
+	"gofs/internal/traverse"
 	"time"
 )
 
-func LongListFormat(results []string) []string {
-	var longList []string
-	for _, file := range results {
-		info, err := os.Stat(file)
-		if err != nil {
-			continue
-		}
-		permissions := info.Mode().String()
-		modTime := info.ModTime().Format(time.RFC822)
-		size := info.Size()
-
-		longList = append(longList, fmt.Sprintf("%c %s %10d %s %s", permissions[0], permissions[1:], size, modTime, file))
+// LongListFormat returns the long list columns of a result: its permissions, size and modification time.
+// Like ls -l, symbolic links are described themselves rather than their targets.
+func LongListFormat(entry traverse.Entry) (string, bool) {
+	info, err := entry.Info()
+	if err != nil {
+		return "", false
 	}
-	return longList
+	permissions := info.Mode().String()
+	modTime := info.ModTime().Format(time.RFC822)
+	size := info.Size()
+
+	return fmt.Sprintf("%c %s %10d %s", permissions[0], permissions[1:], size, modTime), true
 }
@@ -0,0 +1,102 @@
+package formats
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Quoting styles for --quote
+const (
+	QuoteLiteral = "literal" // Names as they are
+	QuoteShell   = "shell"   // Quoted for a POSIX shell when needed, with $'...' for control characters
+	QuoteC       = "c"       // Every name double-quoted, with C escapes
+)
+
+// QuoteFormat quotes every name of a displayed path in the given style, so control characters in
+// names cannot garble the terminal or be mistaken for line breaks. Separators stay outside the
+// quotes, which keeps shell-quoted paths valid shell words.
+func QuoteFormat(file, style string) string {
+	var quote func(string) string
+	switch style {
+	case QuoteShell:
+		quote = shellQuote
+	case QuoteC:
+		quote = func(name string) string { return `"` + escape(name, '"') + `"` }
+	default:
+		return file
+	}
+
+	names := strings.Split(file, string(filepath.Separator))
+	for i, name := range names {
+		if name != "" { // Leading and trailing separators
+			names[i] = quote(name)
+		}
+	}
+	return strings.Join(names, string(filepath.Separator))
+}
+
+// shellQuote leaves names made of safe characters alone, single-quotes those without control
+// characters and uses ANSI-C quoting, understood by bash, zsh and ksh, for the rest.
+func shellQuote(name string) string {
+	if strings.IndexFunc(name, func(r rune) bool { return !isShellSafe(r) }) < 0 {
+		return name
+	}
+	if strings.IndexFunc(name, isControl) < 0 && utf8.ValidString(name) {
+		return "'" + strings.ReplaceAll(name, "'", `'\''`) + "'"
+	}
+	return "$'" + escape(name, '\'') + "'"
+}
+
+// isShellSafe reports whether a character never needs quoting in a shell word.
+func isShellSafe(r rune) bool {
+	if r >= utf8.RuneSelf {
+		return r != utf8.RuneError && unicode.IsPrint(r) && !unicode.IsSpace(r)
+	}
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("._-+,:@%=", r)
+}
+
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0)
+}
+
+// escape writes control characters and bytes that are not valid UTF-8 as C escapes, and
+// backslashes and the quote character with a backslash in front.
+func escape(s string, quote byte) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\%03o`, s[i])
+		case r == '\\' || r == rune(quote):
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case isControl(r):
+			if escaped, ok := cEscapes[r]; ok {
+				b.WriteString(escaped)
+			} else {
+				for _, c := range []byte(s[i : i+size]) {
+					fmt.Fprintf(&b, `\%03o`, c)
+				}
+			}
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// cEscapes are the named escapes of C for control characters.
+var cEscapes = map[rune]string{
+	'\a': `\a`,
+	'\b': `\b`,
+	'\f': `\f`,
+	'\n': `\n`,
+	'\r': `\r`,
+	'\t': `\t`,
+	'\v': `\v`,
+}
@@ -0,0 +1,74 @@
+package formats
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuoteFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		style string
+		want  string
+	}{
+		{"plain-name_1.txt", QuoteShell, "plain-name_1.txt"},
+		{"a+b,c:d@e%f=g", QuoteShell, "a+b,c:d@e%f=g"},
+		{"café.txt", QuoteShell, "café.txt"},
+		{"with space", QuoteShell, "'with space'"},
+		{"it's", QuoteShell, `'it'\''s'`},
+		{"~home", QuoteShell, "'~home'"},
+		{"a~b", QuoteShell, "'a~b'"},
+		{"$HOME", QuoteShell, "'$HOME'"},
+		{"*.go", QuoteShell, "'*.go'"},
+		{"a\tb", QuoteShell, `$'a\tb'`},
+		{"line\nbreak", QuoteShell, `$'line\nbreak'`},
+		{"esc\x1b[31m", QuoteShell, `$'esc\033[31m'`},
+		{"it's\n", QuoteShell, `$'it\'s\n'`},
+		{"back\\slash\x7f", QuoteShell, `$'back\\slash\177'`},
+		{"bad\xffutf8", QuoteShell, `$'bad\377utf8'`},
+		{"non breaking", QuoteShell, "'non breaking'"},
+		{"c1\u0085", QuoteShell, `$'c1\302\205'`},
+
+		{"plain.txt", QuoteC, `"plain.txt"`},
+		{`say "hi"`, QuoteC, `"say \"hi\""`},
+		{"it's", QuoteC, `"it's"`},
+		{"a\tb\\", QuoteC, `"a\tb\\"`},
+		{"bell\a", QuoteC, `"bell\a"`},
+
+		{"line\nbreak", QuoteLiteral, "line\nbreak"},
+		{"line\nbreak", "", "line\nbreak"},
+	}
+	for _, tt := range tests {
+		if got := QuoteFormat(tt.name, tt.style); got != tt.want {
+			t.Errorf("QuoteFormat(%q, %q) = %q, want %q", tt.name, tt.style, got, tt.want)
+		}
+	}
+}
+
+// TestQuoteFormatPath checks that every name of a path is quoted on its own, with the separators left outside.
+func TestQuoteFormatPath(t *testing.T) {
+	path := filepath.Join("my dir", "ok", "it's") + string(filepath.Separator)
+	want := filepath.Join("'my dir'", "ok", `'it'\''s'`) + string(filepath.Separator)
+	if got := QuoteFormat(path, QuoteShell); got != want {
+		t.Errorf("QuoteFormat(%q) = %q, want %q", path, got, want)
+	}
+}
+
+// TestShellQuoteRoundTrip checks that bash reads shell-quoted names back as they are.
+func TestShellQuoteRoundTrip(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not available")
+	}
+	for _, name := range []string{"plain", "with space", "it's", "~home", "$(touch x)", "a\tb\n", "esc\x1b[0m", "back\\slash", "bad\xffutf8", "c1\u0085", "é'\"`"} {
+		out, err := exec.Command(bash, "-c", "printf %s "+shellQuote(name)).Output()
+		if err != nil {
+			t.Errorf("%q: bash failed: %v", name, err)
+			continue
+		}
+		if string(out) != name {
+			t.Errorf("%q quoted as %s reads back as %q", name, shellQuote(name), out)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package formats
+
+import (
+	"fmt"
+	"gofs/internal/traverse"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateData is what a template can show of a result: its JSON record and the parts of its path.
+type TemplateData struct {
+	Record
+	Name    string // Base name, e.g. "main.go"
+	Stem    string // Base name without its extension, e.g. "main"
+	Ext     string // Extension without the dot, e.g. "go", empty for none
+	Parent  string // Directory the result is in
+	RelPath string // Path relative to the search root the result was found under
+}
+
+// Template renders a line for every result, from a --template or a --go-template.
+type Template struct {
+	render func(data TemplateData) (string, error)
+}
+
+// templateField returns the text of a placeholder for a result.
+type templateField func(data TemplateData) string
+
+// templateFields are the placeholders of ParseTemplate, named like the keys of the JSON records.
+var templateFields = map[string]templateField{
+	"path":           func(d TemplateData) string { return d.Path },
+	"absolute_path":  func(d TemplateData) string { return d.AbsolutePath },
+	"type":           func(d TemplateData) string { return d.Type },
+	"size":           func(d TemplateData) string { return strconv.FormatInt(d.Size, 10) },
+	"mode":           func(d TemplateData) string { return d.Mode },
+	"mtime":          func(d TemplateData) string { return d.ModTime.Format(time.RFC3339) },
+	"owner":          func(d TemplateData) string { return d.Owner },
+	"group":          func(d TemplateData) string { return d.Group },
+	"symlink_target": func(d TemplateData) string { return d.SymlinkTarget },
+	"name":           func(d TemplateData) string { return d.Name },
+	"stem":           func(d TemplateData) string { return d.Stem },
+	"ext":            func(d TemplateData) string { return d.Ext },
+	"parent":         func(d TemplateData) string { return d.Parent },
+	"relpath":        func(d TemplateData) string { return d.RelPath },
+}
+
+// templateEscapes are the backslash escapes of ParseTemplate, as shells pass \t as it is
+var templateEscapes = map[byte]byte{'t': '\t', 'n': '\n', '\\': '\\'}
+
+// ParseTemplate parses a template like "{path}\t{size:h}\t{mtime:%Y-%m-%d}". Placeholders are the
+// keys of the JSON records plus name, stem, ext, parent and relpath; {size:h} prints sizes like
+// "4.2M" and {mtime:...} takes a strftime format. {{ and }} print braces, and \t, \n and \\ the
+// characters they stand for.
+func ParseTemplate(text string) (*Template, error) {
+	var fields []templateField
+	var literal strings.Builder
+	flush := func() {
+		s := literal.String()
+		fields = append(fields, func(TemplateData) string { return s })
+		literal.Reset()
+	}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && templateEscapes[text[i+1]] != 0:
+			i++
+			literal.WriteByte(templateEscapes[text[i]])
+		case (c == '{' || c == '}') && i+1 < len(text) && text[i+1] == c:
+			i++
+			literal.WriteByte(c)
+		case c == '}':
+			return nil, fmt.Errorf("invalid template: unexpected } at offset %d, use }} for a brace", i)
+		case c == '{':
+			end := strings.IndexByte(text[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid template: unclosed placeholder at offset %d", i)
+			}
+			field, err := parsePlaceholder(text[i+1 : i+end])
+			if err != nil {
+				return nil, err
+			}
+			flush()
+			fields = append(fields, field)
+			i += end
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	flush()
+
+	return &Template{render: func(data TemplateData) (string, error) {
+		var line strings.Builder
+		for _, field := range fields {
+			line.WriteString(field(data))
+		}
+		return line.String(), nil
+	}}, nil
+}
+
+// parsePlaceholder parses the inside of a placeholder: a field name and an optional modifier.
+func parsePlaceholder(placeholder string) (templateField, error) {
+	name, modifier, hasModifier := strings.Cut(placeholder, ":")
+	field, ok := templateFields[name]
+	if !ok {
+		return nil, fmt.Errorf("invalid template: unknown placeholder {%s}", name)
+	}
+	if !hasModifier {
+		return field, nil
+	}
+
+	switch {
+	case name == "size" && modifier == "h":
+		return func(d TemplateData) string { return HumanSize(d.Size) }, nil
+	case name == "mtime" && modifier != "":
+		return func(d TemplateData) string { return strftime(d.ModTime, modifier) }, nil
+	}
+	return nil, fmt.Errorf("invalid template: unknown modifier in {%s}", placeholder)
+}
+
+// ParseGoTemplate parses a text/template executed with a TemplateData, e.g.
+// '{{.Path}}{{range .Matches}} {{.Line}}{{end}}'. The human function prints sizes like {size:h}.
+func ParseGoTemplate(text string) (*Template, error) {
+	tmpl, err := template.New("go-template").Funcs(template.FuncMap{"human": HumanSize}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Go template: %v", err)
+	}
+	return &Template{render: func(data TemplateData) (string, error) {
+		var line strings.Builder
+		err := tmpl.Execute(&line, data)
+		return line.String(), err
+	}}, nil
+}
+
+// Format renders the line of a result. Results whose metadata cannot be read, or that the
+// template fails on, are left out.
+func (t *Template) Format(entry traverse.Entry) (string, bool) {
+	record, ok := JSONRecord(entry)
+	if !ok {
+		return "", false
+	}
+
+	name := filepath.Base(entry.Path)
+	stem, ext := name, ""
+	if i := strings.LastIndexByte(name, '.'); i > 0 { // Dot files like .bashrc have no extension
+		stem, ext = name[:i], name[i+1:]
+	}
+	relPath, err := filepath.Rel(entry.Root, entry.Path)
+	if err != nil {
+		relPath = entry.Path
+	}
+
+	line, err := t.render(TemplateData{
+		Record:  record,
+		Name:    name,
+		Stem:    stem,
+		Ext:     ext,
+		Parent:  filepath.Dir(entry.Path),
+		RelPath: relPath,
+	})
+	return line, err == nil
+}
+
+// HumanSize prints a size in bytes with a binary unit like ls -h does, e.g. 512, 4.0K or 12M.
+func HumanSize(size int64) string {
+	const units = "KMGTPE"
+	if size < 1024 {
+		return strconv.FormatInt(size, 10)
+	}
+	value := float64(size)
+	unit := -1
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if value < 10 {
+		return fmt.Sprintf("%.1f%c", value, units[unit])
+	}
+	return fmt.Sprintf("%.0f%c", value, units[unit])
+}
+
+// strftimeLayouts are the Go layouts of the strftime conversions
+var strftimeLayouts = map[byte]string{
+	'Y': "2006", 'y': "06", 'm': "01", 'd': "02", 'e': "_2", 'j': "002",
+	'H': "15", 'I': "03", 'M': "04", 'S': "05", 'p': "PM",
+	'b': "Jan", 'h': "Jan", 'B': "January", 'a': "Mon", 'A': "Monday",
+	'Z': "MST", 'z': "-0700", 'F': "2006-01-02", 'T': "15:04:05", 'R': "15:04",
+}
+
+// strftime formats a time with strftime conversions like %Y-%m-%d. %s prints seconds since the
+// epoch and %% a percent sign; other text, including unknown conversions, is printed as it is.
+func strftime(t time.Time, format string) string {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		switch c := format[i]; c {
+		case '%':
+			b.WriteByte('%')
+		case 's':
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		default:
+			if layout, ok := strftimeLayouts[c]; ok {
+				b.WriteString(t.Format(layout))
+			} else {
+				b.WriteByte('%')
+				b.WriteByte(c)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package formats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTemplate(t *testing.T) {
+	data := TemplateData{
+		Record: Record{
+			Path:    "src/main.go",
+			Type:    "file",
+			Size:    4404019,
+			Mode:    "0644",
+			ModTime: time.Date(2024, time.March, 5, 14, 7, 9, 0, time.UTC),
+		},
+		Name:    "main.go",
+		Stem:    "main",
+		Ext:     "go",
+		Parent:  "src",
+		RelPath: "main.go",
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"{path}", "src/main.go"},
+		{"{name} {stem} {ext} {parent} {relpath}", "main.go main go src main.go"},
+		{"{size} {size:h} {mode} {type}", "4404019 4.2M 0644 file"},
+		{"{mtime}", "2024-03-05T14:07:09Z"},
+		{"{mtime:%Y-%m-%d %H:%M}", "2024-03-05 14:07"},
+		{`{name}\t{size}\n`, "main.go\t4404019\n"},
+		{`a\\b \x`, `a\b \x`},
+		{"{{{name}}}", "{main.go}"},
+		{"no placeholders", "no placeholders"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		tmpl, err := ParseTemplate(tt.text)
+		if err != nil {
+			t.Errorf("ParseTemplate(%q) error: %v", tt.text, err)
+			continue
+		}
+		if got, err := tmpl.render(data); err != nil || got != tt.want {
+			t.Errorf("ParseTemplate(%q) renders %q, %v, want %q", tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseTemplateErrors(t *testing.T) {
+	for _, text := range []string{
+		"{path",       // Unclosed placeholder
+		"path}",       // Unexpected brace
+		"{}",          // Empty placeholder
+		"{nosuch}",    // Unknown placeholder
+		"{name:h}",    // Modifier the field does not take
+		"{size:k}",    // Unknown modifier
+		"{mtime:}",    // Empty strftime format
+		"{path}}{ext", // Unexpected brace after a placeholder
+	} {
+		if _, err := ParseTemplate(text); err == nil {
+			t.Errorf("ParseTemplate(%q) should fail", text)
+		}
+	}
+}
+
+func TestStrftime(t *testing.T) {
+	tm := time.Date(2024, time.March, 5, 14, 7, 9, 0, time.UTC)
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%Y-%m-%d", "2024-03-05"},
+		{"%y %e %j", "24  5 065"},
+		{"%H:%M:%S", "14:07:09"},
+		{"%I %p", "02 PM"},
+		{"%a %A %b %h %B", "Tue Tuesday Mar Mar March"},
+		{"%F %T %R", "2024-03-05 14:07:09 14:07"},
+		{"%Z %z", "UTC +0000"},
+		{"%s", "1709647629"},
+		{"100%%", "100%"},
+		{"%Q %", "%Q %"}, // Unknown conversions and a trailing % are printed as they are
+		{"plain", "plain"},
+	}
+	for _, tt := range tests {
+		if got := strftime(tm, tt.format); got != tt.want {
+			t.Errorf("strftime(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{0, "0"},
+		{1023, "1023"},
+		{1024, "1.0K"},
+		{1536, "1.5K"},
+		{10 * 1024, "10K"},
+		{4404019, "4.2M"},
+		{5 << 30, "5.0G"},
+		{1 << 62, "4.0E"},
+	}
+	for _, tt := range tests {
+		if got := HumanSize(tt.size); got != tt.want {
+			t.Errorf("HumanSize(%d) = %q, want %q", tt.size, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"gofs/internal/output/formats"
+	"gofs/internal/traverse"
+	"io"
+)
+
+// WriteJSON writes a JSON record for every streamed entry as it arrives: one per line with ndjson,
+// otherwise as the elements of a JSON array, one per line. Results whose metadata cannot be read are skipped.
+func WriteJSON(ctx context.Context, w io.Writer, results <-chan traverse.Entry, ndjson bool) error {
+	out := bufio.NewWriter(w)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	first := true
+	for entry := range results {
+		record, ok := formats.JSONRecord(entry)
+		if !ok {
+			continue
+		}
+		buf.Reset()
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+
+		switch {
+		case ndjson:
+		case first:
+			out.WriteString("[\n")
+		default:
+			out.WriteString(",\n")
+		}
+		first = false
+		if ndjson {
+			out.Write(buf.Bytes())
+		} else {
+			out.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+		}
+
+		// Flush every record, so consumers like jq see results as soon as they are found
+		if err := out.Flush(); err != nil {
+			return err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if !ndjson {
+		if first {
+			out.WriteString("[")
+		}
+		out.WriteString("\n]\n")
+	}
+	return out.Flush()
+}
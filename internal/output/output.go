@@ -1,29 +1,203 @@
 package output
 
 import (
+	"context"
+	"fmt"
 	"gofs/internal/output/formats"
+	"gofs/internal/registry"
+	"gofs/internal/traverse"
+	"path/filepath"
 )
 
-func FormatResults(results []string, formatOptions map[string]interface{}) []string {
-	formatedResults := results
+// Line is a single line of output. Only the path is colored when printed.
+type Line struct {
+	Info  string         // Metadata printed before the path, e.g. long list columns
+	Path  string         // Path of the entry, empty for separator lines
+	Text  string         // Text printed right after the path, e.g. a matched line
+	Link  string         // URL the path links to in terminals, empty for none
+	Entry traverse.Entry // Result the line belongs to, used to color it; zero for separator lines
+}
+
+// Formatter fills in or rewrites the printed line of a result. It returns false to drop the result.
+type Formatter interface {
+	Format(entry traverse.Entry, line *Line) bool
+}
+
+// FormatterFunc adapts a function to the Formatter interface.
+type FormatterFunc func(entry traverse.Entry, line *Line) bool
+
+func (f FormatterFunc) Format(entry traverse.Entry, line *Line) bool { return f(entry, line) }
+
+// Output formats selected with --format
+const (
+	FormatText   = ""       // Colored lines, shaped by the formatters
+	FormatJSON   = "json"   // A JSON array of records
+	FormatNDJSON = "ndjson" // One JSON record per line
+)
+
+// Options holds the format flags.
+type Options struct {
+	Format       string // --format, one of the Format constants
+	AbsolutePath bool   // -A
+	LongList     bool   // -l
+	Hyperlink    bool   // -L, resolved from its mode by the caller
+	Quote        string // --quote, one of the formats.Quote styles, empty for literal
+	Template     string // --template
+	GoTemplate   string // --go-template
+}
+
+// FormatterBuilder creates a formatter from the options, reporting false when the options do not ask for it.
+type FormatterBuilder = registry.Builder[Options, Formatter]
 
-	// Apply filters one by one
-	for key, value := range formatOptions {
-		switch key {
-		case "AbsolutePath":
-			if absPath, ok := value.(bool); ok && absPath {
-				formatedResults = formats.AbsPathFormat(formatedResults)
+var formatterRegistry registry.Registry[Options, Formatter]
+
+// RegisterFormatter adds a formatter to every search. Formatters run by ascending priority,
+// then by name, so a formatter sees the line as left by the ones before it.
+func RegisterFormatter(name string, priority int, build FormatterBuilder) {
+	formatterRegistry.Register(name, priority, build)
+}
+
+// RegisteredFormatters lists the names of the registered formatters in the order they run.
+func RegisteredFormatters() []string {
+	return formatterRegistry.Names()
+}
+
+// BuildFormatters validates the options and returns the active formatters in their running order.
+// Formatters only shape text output; the JSON formats always describe results in full.
+func BuildFormatters(opts Options) ([]Formatter, error) {
+	switch opts.Format {
+	case FormatText, FormatJSON, FormatNDJSON:
+	default:
+		return nil, fmt.Errorf("invalid format: %q, expected json or ndjson", opts.Format)
+	}
+	return formatterRegistry.Build(opts)
+}
+
+// The built-in formatters
+func init() {
+	RegisterFormatter("absolute-path", 10, func(opts Options) (Formatter, bool, error) {
+		return FormatterFunc(func(entry traverse.Entry, line *Line) bool {
+			var ok bool
+			line.Path, ok = formats.AbsPathFormat(entry, line.Path)
+			return ok
+		}), opts.AbsolutePath, nil
+	})
+	RegisterFormatter("long-list", 20, func(opts Options) (Formatter, bool, error) {
+		return FormatterFunc(func(entry traverse.Entry, line *Line) bool {
+			var ok bool
+			line.Info, ok = formats.LongListFormat(entry)
+			return ok
+		}), opts.LongList, nil
+	})
+	RegisterFormatter("quote", 30, func(opts Options) (Formatter, bool, error) {
+		switch opts.Quote {
+		case "", formats.QuoteLiteral:
+			return nil, false, nil
+		case formats.QuoteShell, formats.QuoteC:
+		default:
+			return nil, false, fmt.Errorf("invalid quoting style: %q, expected shell, c or literal", opts.Quote)
+		}
+		return FormatterFunc(func(entry traverse.Entry, line *Line) bool {
+			line.Path = formats.QuoteFormat(line.Path, opts.Quote)
+			return true
+		}), true, nil
+	})
+	RegisterFormatter("hyperlink", 40, func(opts Options) (Formatter, bool, error) {
+		return FormatterFunc(func(entry traverse.Entry, line *Line) bool {
+			line.Link, _ = formats.HyperlinkFormat(entry.FS, entry.Path)
+			return true
+		}), opts.Hyperlink, nil
+	})
+	RegisterFormatter("template", 50, func(opts Options) (Formatter, bool, error) {
+		var tmpl *formats.Template
+		var err error
+		switch {
+		case opts.Template != "" && opts.GoTemplate != "":
+			return nil, false, fmt.Errorf("--template cannot be combined with --go-template")
+		case opts.Template == "" && opts.GoTemplate == "":
+			return nil, false, nil
+		case opts.Format != FormatText:
+			return nil, false, fmt.Errorf("templates cannot be combined with --format %s", opts.Format)
+		case opts.Template != "":
+			tmpl, err = formats.ParseTemplate(opts.Template)
+		default:
+			tmpl, err = formats.ParseGoTemplate(opts.GoTemplate)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		// The template replaces the whole line, which is printed as it is
+		return FormatterFunc(func(entry traverse.Entry, line *Line) bool {
+			text, ok := tmpl.Format(entry)
+			*line = Line{Text: text, Entry: entry}
+			return ok
+		}), true, nil
+	})
+}
+
+// FormatResults turns every streamed entry into printable lines and applies the formatters to them in order.
+// Directories are displayed with a trailing separator. Entries with content matches produce one
+// line per matched or context line instead of a single path line.
+func FormatResults(ctx context.Context, results <-chan traverse.Entry, formatters []Formatter) <-chan Line {
+	formatedResults := make(chan Line, cap(results))
+
+	// Apply formats one by one
+	go func() {
+		defer close(formatedResults)
+	next:
+		for entry := range results {
+			line := Line{Path: entry.Path, Entry: entry}
+			if entry.IsDir() {
+				line.Path += string(filepath.Separator)
 			}
-		case "LongList":
-			if longList, ok := value.(bool); ok && longList {
-				formatedResults = formats.LongListFormat(formatedResults)
+			for _, formatter := range formatters {
+				if !formatter.Format(entry, &line) {
+					continue next
+				}
+			}
+
+			// Lines without a path, like those of templates, show matches their own way
+			lines := []Line{line}
+			if len(entry.Matches) > 0 && line.Path != "" {
+				lines = matchLines(line, entry.Matches)
+			}
+
+			for _, line := range lines {
+				select {
+				case formatedResults <- line:
+				case <-ctx.Done():
+					return
+				}
 			}
-			// case "Hyperlink":
-			// 	if hyperlink, ok := value.(bool); ok && hyperlink {
-			// 		formatedResults = formats.HyperlinkFormat(formatedResults)
-			// 	}
 		}
-	}
+	}()
 
 	return formatedResults
 }
+
+// matchLines formats content matches grep-style: "path:line:text" for matches and
+// "path-line-text" for context lines, with "--" between non-adjacent groups.
+func matchLines(line Line, matches []traverse.LineMatch) []Line {
+	hasContext := false
+	for _, match := range matches {
+		if match.Context {
+			hasContext = true
+			break
+		}
+	}
+
+	var lines []Line
+	for i, match := range matches {
+		if hasContext && i > 0 && match.Number != matches[i-1].Number+1 {
+			lines = append(lines, Line{Text: "--"})
+		}
+
+		separator := ":"
+		if match.Context {
+			separator = "-"
+		}
+		lines = append(lines, Line{Path: line.Path, Text: fmt.Sprintf("%s%d%s%s", separator, match.Number, separator, match.Text), Link: line.Link, Entry: line.Entry})
+	}
+	return lines
+}
@@ -0,0 +1,34 @@
+package output
+
+import (
+	"context"
+	"gofs/internal/traverse"
+	"sort"
+)
+
+// SortResults waits for the results stream to complete and re-emits the results in sorted order.
+func SortResults(ctx context.Context, results <-chan traverse.Entry) <-chan traverse.Entry {
+	sortedResults := make(chan traverse.Entry, cap(results))
+
+	go func() {
+		defer close(sortedResults)
+
+		var collected []traverse.Entry
+		for result := range results {
+			collected = append(collected, result)
+		}
+		sort.Slice(collected, func(i, j int) bool {
+			return collected[i].Path < collected[j].Path
+		})
+
+		for _, result := range collected {
+			select {
+			case sortedResults <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return sortedResults
+}
@@ -0,0 +1,76 @@
+package registry
+
+import (
+	"sort"
+	"sync"
+)
+
+// Builder creates a component from the parsed options. It returns false when the options
+// do not ask for the component, e.g. because its flag was not given.
+type Builder[O, T any] func(opts O) (T, bool, error)
+
+// Registry holds named builders. Components are always built in the same order: by
+// priority, then by name, so the pipeline does not depend on registration order.
+type Registry[O, T any] struct {
+	mu      sync.Mutex
+	entries []entry[O, T]
+}
+
+type entry[O, T any] struct {
+	name     string
+	priority int
+	build    Builder[O, T]
+}
+
+// Register adds a builder. Registering a name twice replaces the earlier builder.
+func (r *Registry[O, T]) Register(name string, priority int, build Builder[O, T]) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.entries {
+		if r.entries[i].name == name {
+			r.entries[i] = entry[O, T]{name: name, priority: priority, build: build}
+			return
+		}
+	}
+	r.entries = append(r.entries, entry[O, T]{name: name, priority: priority, build: build})
+}
+
+// Names lists the registered names in build order.
+func (r *Registry[O, T]) Names() []string {
+	var names []string
+	for _, e := range r.sorted() {
+		names = append(names, e.name)
+	}
+	return names
+}
+
+// Build runs every builder in order and returns the components the options ask for.
+func (r *Registry[O, T]) Build(opts O) ([]T, error) {
+	var components []T
+	for _, e := range r.sorted() {
+		component, ok, err := e.build(opts)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			components = append(components, component)
+		}
+	}
+	return components, nil
+}
+
+// sorted returns a copy of the entries ordered by priority and name.
+func (r *Registry[O, T]) sorted() []entry[O, T] {
+	r.mu.Lock()
+	entries := append([]entry[O, T](nil), r.entries...)
+	r.mu.Unlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].priority != entries[j].priority {
+			return entries[i].priority < entries[j].priority
+		}
+		return entries[i].name < entries[j].name
+	})
+	return entries
+}
@@ -0,0 +1,145 @@
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"gofs/internal/fsys"
+	"gofs/internal/traverse"
+	"gofs/utils"
+	"io"
+	"regexp"
+)
+
+// binaryProbeSize is how much of a file is inspected for NUL bytes to detect binary files.
+const binaryProbeSize = 8000
+
+// maxLineSize is the longest line a content search can read; files with longer lines are skipped.
+const maxLineSize = 16 * 1024 * 1024
+
+// ContentOptions configures a content search.
+type ContentOptions struct {
+	Pattern       string // Regex the file contents must match
+	CaseSensitive bool
+	ShowLines     bool  // Collect the matching lines instead of stopping at the first match
+	Context       int   // Number of context lines collected around each match
+	MaxFileSize   int64 // Files larger than this are skipped (0 for no limit)
+}
+
+// SearchContents keeps the regular files whose contents match the pattern, using the same
+// worker pool as the name search. Directories, binary files and files over the size cap are
+// dropped. When lines are requested, the matching lines are attached to each entry.
+func SearchContents(ctx context.Context, results <-chan traverse.Entry, maxThreads int, opts ContentOptions) (<-chan traverse.Entry, error) {
+	// Validate maxThreads
+	validThreads, err := utils.ValidateMaxThreads(maxThreads)
+	if err != nil {
+		return nil, fmt.Errorf("error validating maxThreads: %v", err)
+	}
+
+	re, err := CompileContentPattern(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return runWorkers(ctx, results, validThreads, func(entry *traverse.Entry) bool {
+		if entry.IsDir() {
+			return false
+		}
+		matches, ok := searchFile(entry.FS, entry.Path, re, opts)
+		entry.Matches = matches
+		return ok
+	}), nil
+}
+
+// CompileContentPattern compiles the regex file contents must match, with its case sensitivity.
+func CompileContentPattern(opts ContentOptions) (*regexp.Regexp, error) {
+	pattern := opts.Pattern
+	if !opts.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content pattern: %v", err)
+	}
+	return re, nil
+}
+
+// searchFile reports whether the file's contents match, collecting matching and context lines if requested.
+func searchFile(fileSystem fsys.FS, path string, re *regexp.Regexp, opts ContentOptions) ([]traverse.LineMatch, bool) {
+	// Check the type and size before opening, as opening a named pipe blocks until it has a writer
+	info, err := fileSystem.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, false
+	}
+	if opts.MaxFileSize > 0 && info.Size() > opts.MaxFileSize {
+		return nil, false
+	}
+
+	file, err := fileSystem.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+
+	// Skip binary files, detected by a NUL byte near the start
+	reader := bufio.NewReaderSize(file, binaryProbeSize)
+	probe, err := reader.Peek(binaryProbeSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, false
+	}
+	if bytes.IndexByte(probe, 0) != -1 {
+		return nil, false
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	if !opts.ShowLines {
+		for scanner.Scan() {
+			if re.Match(scanner.Bytes()) {
+				return nil, true
+			}
+		}
+		return nil, false
+	}
+
+	var (
+		matches    []traverse.LineMatch
+		before     []traverse.LineMatch // Context lines preceding the next match
+		afterLeft  int                  // Context lines still to collect after the last match
+		lineNumber int
+	)
+
+	for scanner.Scan() {
+		lineNumber++
+		line := traverse.LineMatch{Number: lineNumber, Text: scanner.Text()}
+
+		if re.MatchString(line.Text) {
+			matches = append(matches, before...)
+			matches = append(matches, line)
+			before = before[:0]
+			afterLeft = opts.Context
+			continue
+		}
+
+		line.Context = true
+		if afterLeft > 0 {
+			matches = append(matches, line)
+			afterLeft--
+			continue
+		}
+		if opts.Context > 0 {
+			if len(before) == opts.Context {
+				before = append(before[:0], before[1:]...)
+			}
+			before = append(before, line)
+		}
+	}
+
+	if scanner.Err() != nil {
+		return nil, false
+	}
+
+	return matches, len(matches) > 0
+}
@@ -0,0 +1,46 @@
+package search
+
+import (
+	"gofs/internal/fsys"
+	"io/fs"
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+)
+
+// countingFS counts the files opened through it. Stat does not count, as it does not open files.
+type countingFS struct {
+	fs.FS
+	opens atomic.Int32
+}
+
+func (c *countingFS) Open(name string) (fs.File, error) {
+	c.opens.Add(1)
+	return c.FS.Open(name)
+}
+
+func (c *countingFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(c.FS, name)
+}
+
+func TestSearchFileMaxFileSize(t *testing.T) {
+	files := &countingFS{FS: fstest.MapFS{
+		"small.txt": {Data: []byte("needle\n")},
+		"large.txt": {Data: []byte("needle in a large file\n")},
+	}}
+	fileSystem := fsys.FromFS(files)
+	re := regexp.MustCompile("needle")
+	opts := ContentOptions{MaxFileSize: 10}
+
+	if _, ok := searchFile(fileSystem, "small.txt", re, opts); !ok {
+		t.Error("small.txt: expected a match")
+	}
+	before := files.opens.Load()
+	if _, ok := searchFile(fileSystem, "large.txt", re, opts); ok {
+		t.Error("large.txt: expected to be skipped as larger than MaxFileSize")
+	}
+	if opened := files.opens.Load() - before; opened != 0 {
+		t.Errorf("large.txt was opened %d times, want it skipped before opening", opened)
+	}
+}
@@ -0,0 +1,34 @@
+//go:build unix
+
+package search
+
+import (
+	"gofs/internal/fsys"
+	"path/filepath"
+	"regexp"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestSearchFileNamedPipe checks that a named pipe is skipped instead of blocking until it has a writer.
+func TestSearchFileNamedPipe(t *testing.T) {
+	fifo := filepath.Join(t.TempDir(), "fifo")
+	if err := syscall.Mkfifo(fifo, 0o644); err != nil {
+		t.Skipf("cannot create named pipes: %v", err)
+	}
+
+	done := make(chan bool, 1)
+	go func() {
+		_, ok := searchFile(fsys.OS, fifo, regexp.MustCompile("needle"), ContentOptions{})
+		done <- ok
+	}()
+	select {
+	case ok := <-done:
+		if ok {
+			t.Error("expected the named pipe to be skipped")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("searching a named pipe blocked")
+	}
+}
@@ -1,42 +1,35 @@
 package search
 
 import (
+	"context"
 	"fmt"
-	"gofs/utils"
-	"os"
+	"gofs/internal/glob"
+	"gofs/internal/traverse"
 	"path/filepath"
 	"regexp"
 	"sync"
 )
 
-// SearchWithThreads performs parallel search on traversal results.
-func SearchWithThreads(pattern string, traversalResults []string, validThreads int, isGlob bool) ([]string, error) {
+// SearchWithThreads performs parallel search on streamed traversal results.
+// Matching entries are sent to the returned channel, which is closed once the input is drained.
+func SearchWithThreads(ctx context.Context, pattern string, traversalResults <-chan traverse.Entry, validThreads int, isGlob bool, caseSensitive bool, fullPath bool) (<-chan traverse.Entry, error) {
 
-	// Compile regex if the pattern is not a glob
-	var re *regexp.Regexp
-	var err error
-
-	if !isGlob {
-		re, err = regexp.Compile(pattern)
-		if err != nil {
-			return nil, fmt.Errorf("invalid regex pattern: %v", err)
-		}
-	} else if !utils.IsValidGlob(pattern) {
-		return nil, fmt.Errorf("invalid glob pattern: %s", pattern)
+	// Compile the pattern into a name matcher
+	match, err := compileMatcher(pattern, isGlob, caseSensitive)
+	if err != nil {
+		return nil, err
 	}
 
-	// Channels for parallel processing
-	workChan := make(chan string, len(traversalResults))
-	resultsChan := make(chan string, len(traversalResults))
-	errChan := make(chan error, 1)
+	return runWorkers(ctx, traversalResults, validThreads, func(entry *traverse.Entry) bool {
+		return matchFileOrDir(*entry, match, fullPath)
+	}), nil
+}
 
-	// Populate the work channel
-	go func() {
-		defer close(workChan)
-		for _, file := range traversalResults {
-			workChan <- file
-		}
-	}()
+// runWorkers runs keep on every streamed entry using validThreads parallel workers.
+// Entries for which keep returns true are sent to the returned channel, which is closed
+// once the input is drained. keep may update the entry before it is sent on.
+func runWorkers(ctx context.Context, entries <-chan traverse.Entry, validThreads int, keep func(*traverse.Entry) bool) <-chan traverse.Entry {
+	resultsChan := make(chan traverse.Entry, validThreads)
 
 	// Worker function
 	var wg sync.WaitGroup
@@ -45,9 +38,14 @@ func SearchWithThreads(pattern string, traversalResults []string, validThreads i
 	for i := 0; i < validThreads; i++ {
 		go func() {
 			defer wg.Done()
-			for file := range workChan {
-				if matchFileOrDir(file, pattern, re, isGlob) {
-					resultsChan <- file
+			for entry := range entries {
+				if !keep(&entry) {
+					continue
+				}
+				select {
+				case resultsChan <- entry:
+				case <-ctx.Done():
+					return
 				}
 			}
 		}()
@@ -59,46 +57,36 @@ func SearchWithThreads(pattern string, traversalResults []string, validThreads i
 		close(resultsChan)
 	}()
 
-	// Collect results with de-duplication
-	resultsSet := make(map[string]struct{})
-	var results []string
-	for result := range resultsChan {
-		if _, exists := resultsSet[result]; !exists {
-			resultsSet[result] = struct{}{}
-			results = append(results, result)
+	return resultsChan
+}
+
+// compileMatcher compiles a regex or glob pattern into a function matching a single name or path.
+func compileMatcher(pattern string, isGlob bool, caseSensitive bool) (func(string) bool, error) {
+	if isGlob {
+		g, err := glob.CompileWithOptions(pattern, glob.Options{IgnoreCase: !caseSensitive})
+		if err != nil {
+			return nil, err
 		}
+		return func(name string) bool {
+			return g.Match(filepath.ToSlash(name))
+		}, nil
 	}
 
-	// Check for errors
-	select {
-	case err := <-errChan:
-		return nil, err
-	default:
+	if !caseSensitive {
+		pattern = "(?i)" + pattern
 	}
-
-	return results, nil
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex pattern: %v", err)
+	}
+	return re.MatchString, nil
 }
 
 // matchFileOrDir checks if a file or directory matches the pattern.
-func matchFileOrDir(file string, pattern string, re *regexp.Regexp, isGlob bool) bool {
-
-	if stat, err := os.Stat(file); err == nil {
-		if stat.IsDir() {
-			// Match directory name
-			if isGlob {
-				matched, _ := filepath.Match(pattern, filepath.Base(file))
-				return matched
-			}
-			return re.MatchString(filepath.Base(file))
-		}
-
-		// Match file name
-		if isGlob {
-			matched, _ := filepath.Match(pattern, filepath.Base(file))
-			return matched
-		} else {
-			return re.MatchString(file) || re.MatchString(filepath.Base(file))
-		}
+// The pattern is matched against the base name, or against the whole path in full-path mode.
+func matchFileOrDir(entry traverse.Entry, match func(string) bool, fullPath bool) bool {
+	if fullPath {
+		return match(entry.Path)
 	}
-	return false
+	return match(filepath.Base(entry.Path))
 }
@@ -1,29 +1,31 @@
 package search
 
 import (
+	"context"
 	"fmt"
+	"gofs/internal/traverse"
 	"gofs/utils"
 )
 
 // SearchPattern orchestrates the search logic, validating threads and leveraging parallel search.
-func SearchPattern(pattern string, traversalResults []string, maxThreads int, isGlob bool) ([]string, error) {
+func SearchPattern(ctx context.Context, pattern string, traversalResults <-chan traverse.Entry, maxThreads int, isGlob bool, caseSensitive bool, fullPath bool) (<-chan traverse.Entry, error) {
 	// Validate maxThreads
 	validThreads, err := utils.ValidateMaxThreads(maxThreads)
 	if err != nil {
 		return nil, fmt.Errorf("error validating maxThreads: %v", err)
 	}
 
-	// If the pattern is ".", return traversal results directly
+	// If the pattern is ".", pass traversal results through directly
 	if pattern == "." {
 		return traversalResults, nil
 	}
 
 	// Execute the search using parallel threads
-	searchResults, err := SearchWithThreads(pattern, traversalResults, validThreads, isGlob)
+	searchResults, err := SearchWithThreads(ctx, pattern, traversalResults, validThreads, isGlob, caseSensitive, fullPath)
 	if err != nil {
 		return nil, fmt.Errorf("error during search: %v", err)
 	}
 
-	// Return the search results
+	// Return the search results stream
 	return searchResults, nil
 }
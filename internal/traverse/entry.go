@@ -0,0 +1,59 @@
+package traverse
+
+import (
+	"gofs/internal/fsys"
+	"io/fs"
+	"sync"
+)
+
+// Entry is a single traversal result streamed through the search pipeline.
+type Entry struct {
+	Path     string      // Root joined with the path relative to it
+	Root     string      // Search root the entry was found under
+	DirEntry fs.DirEntry // Directory entry as read during traversal
+	Matches  []LineMatch // Lines matched by a content search, if requested
+	FS       fsys.FS     // File system the entry was found in
+
+	info *lazyInfo // Shared by every copy of the entry
+}
+
+// lazyInfo caches the file information of an entry once it is first asked for.
+type lazyInfo struct {
+	once sync.Once
+	info fs.FileInfo
+	err  error
+}
+
+// NewEntry creates an entry whose file information is loaded on first use.
+func NewEntry(fileSystem fsys.FS, path, root string, d fs.DirEntry) Entry {
+	return Entry{Path: path, Root: root, DirEntry: d, FS: fileSystem, info: &lazyInfo{}}
+}
+
+// LineMatch is a line of a file matched by a content search, or a context line around a match.
+type LineMatch struct {
+	Number  int    // 1-based line number
+	Text    string // Line contents without the line terminator
+	Context bool   // Line is only shown as context around a match
+}
+
+// IsDir reports whether the entry is a directory.
+func (e Entry) IsDir() bool {
+	return e.DirEntry.IsDir()
+}
+
+// Name returns the base name of the entry.
+func (e Entry) Name() string {
+	return e.DirEntry.Name()
+}
+
+// Info returns the file information of the entry. Like fs.DirEntry.Info, it describes a
+// symbolic link itself rather than its target. It is loaded on first use and cached.
+func (e Entry) Info() (fs.FileInfo, error) {
+	if e.info == nil {
+		return e.DirEntry.Info()
+	}
+	e.info.once.Do(func() {
+		e.info.info, e.info.err = e.DirEntry.Info()
+	})
+	return e.info.info, e.info.err
+}
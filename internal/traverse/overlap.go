@@ -0,0 +1,94 @@
+package traverse
+
+import (
+	"gofs/internal/fsys"
+	"math"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// visitTracker de-duplicates entries reached through overlapping search roots.
+// It is only used when one root is nested inside another, so the common case
+// of disjoint roots does not need to remember every visited path.
+type visitTracker struct {
+	mu      sync.Mutex
+	emitted map[string]struct{} // Canonical paths already streamed
+	budgets map[string]int      // Remaining depth a directory was already descended with
+}
+
+func newVisitTracker() *visitTracker {
+	return &visitTracker{
+		emitted: make(map[string]struct{}),
+		budgets: make(map[string]int),
+	}
+}
+
+// emit reports whether the entry at the canonical path has not been streamed yet, and records it.
+func (v *visitTracker) emit(key string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if _, exists := v.emitted[key]; exists {
+		return false
+	}
+	v.emitted[key] = struct{}{}
+	return true
+}
+
+// descend reports whether a directory should be read with the given remaining depth.
+// A directory is read again only when it is reached with more depth left than before,
+// so overlapping roots with depth limits still see their full subtree.
+func (v *visitTracker) descend(key string, budget int) bool {
+	if budget == -1 {
+		budget = math.MaxInt
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if previous, exists := v.budgets[key]; exists && previous >= budget {
+		return false
+	}
+	v.budgets[key] = budget
+	return true
+}
+
+// canonicalRoot resolves a root to an absolute path with symlinks evaluated. Paths on other
+// file systems are only cleaned, as they are already relative to the file system's root.
+func canonicalRoot(fileSystem fsys.FS, root string) string {
+	if !fileSystem.Native() {
+		return filepath.Clean(root)
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return filepath.Clean(root)
+	}
+	if realRoot, err := filepath.EvalSymlinks(absRoot); err == nil {
+		return realRoot
+	}
+	return absRoot
+}
+
+// rootsOverlap reports whether any canonical root is equal to or nested inside another.
+func rootsOverlap(canonicalRoots []string) bool {
+	for i, a := range canonicalRoots {
+		for j, b := range canonicalRoots {
+			if i != j && isWithin(a, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isWithin reports whether path is equal to or below dir.
+func isWithin(path, dir string) bool {
+	if path == dir || dir == "." { // The root of a non-native file system holds everything
+		return true
+	}
+	if !strings.HasSuffix(dir, string(filepath.Separator)) {
+		dir += string(filepath.Separator)
+	}
+	return strings.HasPrefix(path, dir)
+}
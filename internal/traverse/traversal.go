@@ -3,64 +3,70 @@ package traverse
 import (
 	"context"
 	"fmt"
+	"gofs/internal/archive"
+	"gofs/internal/fsys"
+	"gofs/internal/ignore"
 	"gofs/utils"
-	"sync"
 )
 
-// TraverseAndValidate performs directory traversal and validates the pathname.
-// Returns validated paths and an error if the pathname is invalid.
-func TraverseAndValidate(root string, pathname string, depth, maxThreads int) ([]string, error) {
+// TraverseAndValidate validates the root pathnames and starts the directory traversal from all of them on fileSystem.
+// Entries are streamed on the returned channel as soon as they are found. The error channel
+// receives the traversal error, if any, and is closed once the traversal has finished.
+// Problems that do not stop the traversal are reported to warn, if set.
+func TraverseAndValidate(ctx context.Context, fileSystem fsys.FS, roots []string, depth, maxThreads int, hidden, archives bool, ignoreOptions ignore.Options, warn func(error)) (<-chan Entry, <-chan error, error) {
+	// Validate the root pathnames
+	for _, root := range roots {
+		if err := ValidateRoot(fileSystem, root, archives); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Validate depth
 	validDepth, err := utils.ValidateDepth(depth)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Validate maxThreads
 	validThreads, err := utils.ValidateMaxThreads(maxThreads)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Check for hidden and ignore flags
-	hidden := utils.CheckHiddenFlag()
-	ignore := utils.CheckIgnoreFlag()
-
-	// Create a context to manage cancellation
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Channel for traversal results
-	results := make(chan string, maxThreads)
+	// Validate custom ignore files
+	if err := utils.ValidateIgnoreFiles(ignoreOptions.Files); err != nil {
+		return nil, nil, err
+	}
 
-	// Perform traversal
-	var allPaths []string
-	var wg sync.WaitGroup
+	// Channels for traversal results and errors
+	results := make(chan Entry, validThreads)
+	errChan := make(chan error, 1)
 
-	// Collect results from traversal
-	wg.Add(1)
+	// Run the traversal logic
 	go func() {
-		defer wg.Done()
-		for path := range results {
-			allPaths = append(allPaths, path)
+		defer close(errChan)
+		if err := TraverseAndStream(ctx, fileSystem, roots, validDepth, results, validThreads, hidden, archives, ignoreOptions, warn); err != nil {
+			errChan <- fmt.Errorf("error during traversal: %v", err)
 		}
 	}()
 
-	// Call the traversal logic
-	if err := TraverseAndStream(ctx, root, validDepth, results, validThreads, hidden, ignore); err != nil {
-		cancel()
-		wg.Wait()
-		return nil, fmt.Errorf("error during traversal: %v", err)
-	}
-
-	// Wait for collection to finish
-	wg.Wait()
+	return results, errChan, nil
+}
 
-	// Validate the pathname
-	validPaths, found := utils.ValidatePathname(allPaths, pathname)
-	if !found {
-		return nil, fmt.Errorf("pathname doesn't exist in the current directory: %s", pathname)
+// ValidateRoot checks that a search root is a readable directory or, when archives are searched,
+// an archive, which is then searched like a directory.
+func ValidateRoot(fileSystem fsys.FS, root string, archives bool) error {
+	if isArchiveRoot(fileSystem, root, archives) {
+		return nil
 	}
+	return utils.ValidatePathnameIn(fileSystem, root)
+}
 
-	return validPaths, nil
+// isArchiveRoot reports whether a root names an archive to search.
+func isArchiveRoot(fileSystem fsys.FS, root string, archives bool) bool {
+	if !archives || !archive.IsArchive(root) {
+		return false
+	}
+	info, err := fileSystem.Stat(root)
+	return err == nil && info.Mode().IsRegular()
 }
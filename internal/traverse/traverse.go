@@ -2,122 +2,289 @@ package traverse
 
 import (
 	"context"
+	"fmt"
+	"gofs/internal/archive"
+	"gofs/internal/fsys"
+	"gofs/internal/ignore"
 	"gofs/utils"
-	"io/fs"
 	"path/filepath"
 	"strings"
 	"sync"
 )
 
-// TraverseAndStream traverses the directory tree starting from root, up to a specified depth.
-// Streams files and directories to a results channel for further processing.
-func TraverseAndStream(ctx context.Context, root string, depth int, results chan<- string, maxThreads int, hidden bool, ignore bool) error {
+// workItem is a directory waiting to be read, along with the depth of its children below the root.
+type workItem struct {
+	dir       string
+	root      string
+	canonical string // Resolved absolute path, used to de-duplicate overlapping roots and match ignore rules
+	depth     int
+	rules     *ignore.Stack // Ignore rules in effect above this directory, nil when ignore files are not respected or inside archives
+}
 
-	var wg sync.WaitGroup
-	workChan := make(chan string, maxThreads)
-	errChan := make(chan error, 1)
+// workQueue is an unbounded queue of directories shared by all traversal workers.
+// It closes itself once every queued directory has been processed.
+type workQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	items   []workItem
+	pending int // Directories queued or currently being read
+	closed  bool
+}
+
+func newWorkQueue() *workQueue {
+	q := &workQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push adds a directory to the queue. It must be called before the parent item is marked done.
+func (q *workQueue) push(item workItem) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return
+	}
+	q.items = append(q.items, item)
+	q.pending++
+	q.cond.Signal()
+}
+
+// pop blocks until a directory is available or the queue is closed.
+func (q *workQueue) pop() (workItem, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if q.closed {
+		return workItem{}, false
+	}
+
+	item := q.items[len(q.items)-1] // LIFO keeps the queue short on deep trees
+	q.items = q.items[:len(q.items)-1]
+	return item, true
+}
+
+// done marks a popped directory as processed and closes the queue when no work is left.
+func (q *workQueue) done() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pending--
+	if q.pending == 0 {
+		q.closed = true
+		q.cond.Broadcast()
+	}
+}
+
+// close stops the queue early, waking up every waiting worker.
+func (q *workQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+// traversal holds the state shared by all workers of a single TraverseAndStream call.
+type traversal struct {
+	fsys     fsys.FS
+	depth    int
+	hidden   bool
+	archives bool // Descend into archives as if they were directories
+	queue    *workQueue
+	results  chan<- Entry
+	visited  *visitTracker // Nil unless the roots overlap
+	warn     func(error)   // Reports problems that do not stop the traversal, nil to ignore them
+}
+
+// TraverseAndStream traverses the directory trees of fileSystem starting from each root, up to a specified depth.
+// The roots themselves are not streamed; every entry below a root is streamed with its path set to
+// the root joined with its relative path, so results are absolute when the root is absolute.
+// With archives set, zip and tar archives, found below the roots or given as roots, are traversed
+// like directories and their members are streamed as "release.tar.gz!/bin/app", readable through
+// the entries' file system.
+// Every discovered subdirectory of every root becomes its own work item, so up to maxThreads
+// directories are read in parallel. Entries reached through overlapping roots are streamed once.
+// Archives that cannot be read are reported to warn, if set, and are left as ordinary files;
+// directories below the roots that cannot be read are reported to warn and skipped.
+// The results channel is closed once the traversal is complete, and the first error encountered
+// is returned after all workers have stopped.
+func TraverseAndStream(ctx context.Context, fileSystem fsys.FS, roots []string, depth int, results chan<- Entry, maxThreads int, hidden, archives bool, ignoreOptions ignore.Options, warn func(error)) error {
+	defer close(results)
+
+	// Roots are resolved on the file system itself, only the entries below them can be archive members
+	entryFS := fileSystem
+	if archives {
+		entryFS = archive.NewFS(fileSystem)
+	}
+
+	queue := newWorkQueue()
+	stop := context.AfterFunc(ctx, queue.close)
+	defer stop()
+
+	t := &traversal{
+		fsys:     entryFS,
+		depth:    depth,
+		hidden:   hidden,
+		archives: archives,
+		queue:    queue,
+		results:  results,
+		warn:     warn,
+	}
+
+	// Resolve the roots, dropping exact duplicates
+	var seeds []workItem
+	var canonicalRoots []string
+	seen := make(map[string]struct{})
+	for _, root := range roots {
+		canonical := canonicalRoot(fileSystem, root)
+		inArchive := isArchiveRoot(fileSystem, root, archives)
+		if inArchive {
+			root, canonical = root+archive.Separator, canonical+archive.Separator
+		}
+		if _, exists := seen[canonical]; exists {
+			continue
+		}
+		seen[canonical] = struct{}{}
+		canonicalRoots = append(canonicalRoots, canonical)
+		seed := workItem{dir: root, root: root, canonical: canonical, depth: 0}
+		if ignoreOptions.Enabled() && !inArchive {
+			seed.rules = ignore.NewRootStack(fileSystem, canonical, ignoreOptions)
+		}
+		seeds = append(seeds, seed)
+	}
+	if rootsOverlap(canonicalRoots) {
+		t.visited = newVisitTracker()
+	}
+
+	var (
+		firstErr error
+		errOnce  sync.Once
+	)
+	recordErr := func(err error) {
+		errOnce.Do(func() { firstErr = err })
+	}
+
+	// Seed the work queue with the root directories before any worker starts, so a worker
+	// finishing the first root cannot close the queue while the others are still being queued
+	for _, seed := range seeds {
+		if t.visited != nil {
+			t.visited.descend(seed.canonical, depth)
+		}
+		queue.push(seed)
+	}
+	if len(seeds) == 0 {
+		queue.close()
+	}
 
 	// Worker function to process directories
+	var wg sync.WaitGroup
 	wg.Add(maxThreads)
 	for i := 0; i < maxThreads; i++ {
-		go func(workerID int) {
+		go func() {
 			defer wg.Done()
-			for dir := range workChan {
-
-				// Stream the directory itself
-				// Stream the directory itself, but skip "."
-				if dir != "." {
-					select {
-					case results <- dir:
-					case <-ctx.Done():
-						return
-					}
+			for {
+				item, ok := queue.pop()
+				if !ok {
+					return
 				}
-
-				err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
-					if err != nil {
-						// Send error to errChan and stop further processing
-						select {
-						case errChan <- err:
-						default:
-						}
-						return filepath.SkipDir
-					}
-
-					// Skip hidden files if hidden flag is not set
-					if !hidden && utils.IsHidden(path) {
-						if d.IsDir() {
-							return filepath.SkipDir
-						}
-						return nil
-					}
-
-					// Skip ignored files (implement .ignore file parsing logic)
-					if !ignore && utils.IsIgnored(path) {
-						return nil
-					}
-
-					// Skip the root directory itself (already streamed)
-					if path == root {
-						return nil
-					}
-
-					// Calculate depth
-					relativePath, err := filepath.Rel(root, path)
-					if err != nil {
-						return nil
-					}
-					currentDepth := strings.Count(relativePath, string(filepath.Separator))
-
-					// Skip entries beyond the specified depth
-					if depth != -1 && currentDepth > depth {
-						if d.IsDir() {
-							return filepath.SkipDir
-						}
-						return nil
-					}
-
-					// Stream the result immediately
-					select {
-					case results <- path:
-					case <-ctx.Done(): // Stop if context is canceled
-						return context.Canceled
-					}
-
-					return nil
-				})
-				if err != nil {
-					select {
-					case errChan <- err:
-					default:
-					}
+				if err := t.processDir(ctx, item); err != nil {
+					recordErr(err)
 				}
+				queue.done()
 			}
-		}(i)
+		}()
 	}
 
-	// Seed the work channel with the root directory
-	go func() {
-		defer close(workChan)
-		workChan <- root
-	}()
-
 	// Wait for all workers to finish
-	go func() {
-		wg.Wait()
-		close(results)
-		close(errChan)
-	}()
-
-	// Check for errors
-	select {
-	case err := <-errChan:
-		if err != nil {
-			return err
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return firstErr
+}
+
+// processDir reads a single directory, streams its entries and queues its subdirectories.
+func (t *traversal) processDir(ctx context.Context, item workItem) error {
+	// ReadDir returns the entries read before an error, so keep going with those
+	entries, readErr := t.fsys.ReadDir(item.dir)
+
+	// A corrupt archive, or a file merely named like one, does not fail the search
+	if readErr != nil && strings.HasSuffix(item.dir, archive.Separator) {
+		t.report(fmt.Errorf("%v, searched as an ordinary file", readErr))
+		return nil
+	}
+
+	// Only an unreadable root fails the search, a directory below it is reported and skipped
+	if readErr != nil && item.dir != item.root {
+		t.report(readErr)
+		readErr = nil
+	}
+
+	// Stack the ignore files found in this directory on top of the inherited rules
+	rules := item.rules
+	if rules != nil {
+		rules = rules.Child(item.canonical, entries)
+	}
+
+	for _, d := range entries {
+		path := filepath.Join(item.dir, d.Name())
+		canonical := filepath.Join(item.canonical, d.Name())
+
+		// Skip hidden files if hidden flag is not set
+		if !t.hidden && utils.IsHidden(path) {
+			continue
+		}
+
+		// Skip ignored files and do not descend into ignored directories
+		if rules != nil && rules.Ignored(canonical, d.IsDir()) {
+			continue
+		}
+
+		// Stream the result immediately, unless another root already did
+		if t.visited == nil || t.visited.emit(canonical) {
+			select {
+			case t.results <- NewEntry(t.fsys, path, item.root, d):
+			case <-ctx.Done(): // Stop if context is canceled
+				return ctx.Err()
+			}
+		}
+
+		// Queue subdirectories, and archives when searching them, that are still within the depth limit
+		dir, dirCanonical, dirRules := path, canonical, rules
+		if t.archives && d.Type().IsRegular() && archive.IsArchive(d.Name()) {
+			// Ignore files inside an archive are not part of the tree being searched
+			dir, dirCanonical, dirRules = path+archive.Separator, canonical+archive.Separator, nil
+		} else if !d.IsDir() {
+			continue
+		}
+		if t.depth != -1 && item.depth+1 > t.depth {
+			continue
+		}
+		if t.visited != nil && !t.visited.descend(dirCanonical, remainingDepth(t.depth, item.depth+1)) {
+			continue
 		}
-	case <-ctx.Done():
-		return context.Canceled
+		t.queue.push(workItem{dir: dir, root: item.root, canonical: dirCanonical, depth: item.depth + 1, rules: dirRules})
 	}
 
-	return nil
+	return readErr
+}
+
+// report passes a problem that does not stop the traversal to the warn callback, if set.
+func (t *traversal) report(err error) {
+	if t.warn != nil {
+		t.warn(err)
+	}
+}
+
+// remainingDepth returns how many more levels may be traversed below the given depth (-1 for no limit).
+func remainingDepth(maxDepth, depth int) int {
+	if maxDepth == -1 {
+		return -1
+	}
+	return maxDepth - depth
 }
@@ -0,0 +1,155 @@
+package traverse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gofs/internal/fsys"
+	"gofs/internal/ignore"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+// TestTraverseManyRoots checks that every root is searched when workers finish the first roots
+// while the others are still being queued.
+func TestTraverseManyRoots(t *testing.T) {
+	const numRoots = 300
+
+	dir := t.TempDir()
+	var roots []string
+	for i := 0; i < numRoots; i++ {
+		root := filepath.Join(dir, fmt.Sprintf("r%03d", i))
+		if err := os.Mkdir(root, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, "file"), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		roots = append(roots, root)
+	}
+
+	for run := 0; run < 20; run++ {
+		results := make(chan Entry, 8)
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- TraverseAndStream(context.Background(), fsys.OS, roots, -1, results, 8, false, false, ignore.Options{NoIgnore: true}, nil)
+		}()
+
+		count := 0
+		for range results {
+			count++
+		}
+		if err := <-errChan; err != nil {
+			t.Fatalf("run %d: unexpected error: %v", run, err)
+		}
+		if count != numRoots {
+			t.Fatalf("run %d: got %d results, want %d", run, count, numRoots)
+		}
+	}
+}
+
+// TestTraverseBrokenArchive checks that an archive that cannot be read is reported as a warning
+// and streamed as an ordinary file instead of failing the traversal.
+func TestTraverseBrokenArchive(t *testing.T) {
+	fileSystem := fsys.FromFS(fstest.MapFS{
+		"bad.zip":   {Data: []byte("not a zip archive")},
+		"dir/a.txt": {},
+	})
+
+	var warnings []error
+	var mu sync.Mutex
+	warn := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		warnings = append(warnings, err)
+	}
+
+	results := make(chan Entry, 8)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- TraverseAndStream(context.Background(), fileSystem, []string{"."}, -1, results, 2, false, true, ignore.Options{NoIgnore: true}, warn)
+	}()
+
+	var paths []string
+	for entry := range results {
+		paths = append(paths, entry.Path)
+	}
+	if err := <-errChan; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	slices.Sort(paths)
+	if want := []string{"bad.zip", "dir", "dir/a.txt"}; !slices.Equal(paths, want) {
+		t.Errorf("got %q, want %q", paths, want)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), "bad.zip") {
+		t.Errorf("got warnings %v, want one about bad.zip", warnings)
+	}
+}
+
+// lockedFS fails to read the directories named locked, like a directory without read permission.
+type lockedFS struct {
+	fstest.MapFS
+}
+
+func (l lockedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if path.Base(name) == "locked" {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrPermission}
+	}
+	return l.MapFS.ReadDir(name)
+}
+
+// TestTraverseUnreadableDir checks that a directory below a root that cannot be read is reported
+// as a warning and skipped, while an unreadable root fails the traversal.
+func TestTraverseUnreadableDir(t *testing.T) {
+	fileSystem := fsys.FromFS(lockedFS{fstest.MapFS{
+		"locked/secret.txt": {},
+		"dir/locked/a.txt":  {},
+		"dir/b.txt":         {},
+	}})
+
+	traverse := func(root string) ([]string, []error, error) {
+		var warnings []error
+		var mu sync.Mutex
+		warn := func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			warnings = append(warnings, err)
+		}
+
+		results := make(chan Entry, 8)
+		errChan := make(chan error, 1)
+		go func() {
+			errChan <- TraverseAndStream(context.Background(), fileSystem, []string{root}, -1, results, 2, false, false, ignore.Options{NoIgnore: true}, warn)
+		}()
+
+		var paths []string
+		for entry := range results {
+			paths = append(paths, entry.Path)
+		}
+		slices.Sort(paths)
+		return paths, warnings, <-errChan
+	}
+
+	paths, warnings, err := traverse(".")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"dir", "dir/b.txt", "dir/locked", "locked"}; !slices.Equal(paths, want) {
+		t.Errorf("got %q, want %q", paths, want)
+	}
+	if len(warnings) != 2 || !errors.Is(warnings[0], fs.ErrPermission) || !errors.Is(warnings[1], fs.ErrPermission) {
+		t.Errorf("got warnings %v, want one for each locked directory", warnings)
+	}
+
+	if _, _, err := traverse("locked"); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("got error %v for an unreadable root, want a permission error", err)
+	}
+}
@@ -0,0 +1,72 @@
+package gofs
+
+import (
+	"gofs/internal/traverse"
+	"io/fs"
+)
+
+// Entry is a search result: its path, the fs.DirEntry read during traversal and, for content
+// searches, the matched lines. Entry.Info loads the fs.FileInfo on first use.
+type Entry struct {
+	Path     string      // Root joined with the path relative to the root
+	Root     string      // Search root the entry was found under
+	DirEntry fs.DirEntry // Directory entry as read during traversal
+	Matches  []LineMatch // Lines matched by a content search, when ShowLines or ContextLines is set
+
+	// FS is the file system the entry was found in, which opens it by its Path. On the operating
+	// system's file system that is an operating system path rather than an io/fs one, and inside
+	// archives it is a member path like "release.tar.gz!/bin/app".
+	FS fs.FS
+
+	info func() (fs.FileInfo, error) // Loads and caches the file information, nil for entries built by callers
+}
+
+// LineMatch is a line matched by a content search, or a context line around a match.
+type LineMatch struct {
+	Number  int    // 1-based line number
+	Text    string // Line contents without the line terminator
+	Context bool   // Line is only shown as context around a match
+}
+
+// newEntry describes a traversal entry, sharing its cached file information.
+func newEntry(e traverse.Entry) Entry {
+	var matches []LineMatch
+	if len(e.Matches) > 0 {
+		matches = make([]LineMatch, len(e.Matches))
+		for i, match := range e.Matches {
+			matches[i] = LineMatch(match)
+		}
+	}
+	return Entry{
+		Path:     e.Path,
+		Root:     e.Root,
+		DirEntry: e.DirEntry,
+		Matches:  matches,
+		FS:       e.FS,
+		info:     e.Info,
+	}
+}
+
+// IsDir reports whether the entry is a directory.
+func (e Entry) IsDir() bool {
+	return e.DirEntry.IsDir()
+}
+
+// Name returns the base name of the entry.
+func (e Entry) Name() string {
+	return e.DirEntry.Name()
+}
+
+// Info returns the file information of the entry. Like fs.DirEntry.Info, it describes a
+// symbolic link itself rather than its target. It is loaded on first use and cached.
+func (e Entry) Info() (fs.FileInfo, error) {
+	if e.info == nil {
+		return e.DirEntry.Info()
+	}
+	return e.info()
+}
+
+// Open opens the entry for reading, also when it is an archive member.
+func (e Entry) Open() (fs.File, error) {
+	return e.FS.Open(e.Path)
+}
@@ -0,0 +1,293 @@
+// Package gofs finds files and directories the way the gofs command does, for use from Go programs.
+//
+//	finder, err := gofs.New(gofs.Options{Roots: []string{"src"}, Glob: "*.go"})
+//	if err != nil {
+//		return err
+//	}
+//	for entry, err := range finder.Find(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(entry.Path)
+//	}
+//
+// Directories are read in parallel and results are streamed as soon as they are found,
+// so their order is not deterministic unless Options.Sort is set.
+package gofs
+
+import (
+	"context"
+	"fmt"
+	"gofs/internal/filter"
+	"gofs/internal/fsys"
+	"gofs/internal/ignore"
+	"gofs/internal/output"
+	"gofs/internal/search"
+	"gofs/internal/traverse"
+	"gofs/utils"
+	"io/fs"
+	"iter"
+	"runtime"
+	"time"
+)
+
+// IgnoreOptions selects which ignore files are respected.
+type IgnoreOptions struct {
+	NoIgnore    bool     // Skip .gitignore, .ignore and the repository-wide git rules
+	NoIgnoreVCS bool     // Skip .gitignore, .git/info/exclude and core.excludesFile
+	Files       []string // Custom ignore files, matched relative to each search root
+}
+
+// FilterOptions holds the built-in filters, with the same syntax as the matching command line flags.
+// Empty values leave the corresponding filter out.
+type FilterOptions struct {
+	Extensions    []string  // -e, matched case-insensitively
+	FileTypes     []string  // -t, any of them matches
+	Excludes      []string  // -x globs
+	Sizes         []string  // --size constraints, all of them must hold
+	ChangedWithin string    // --changed-within duration or date
+	ChangedBefore string    // --changed-before duration or date
+	Newer         string    // --newer reference file
+	Older         string    // --older reference file
+	TimeField     string    // --time-field, mtime when empty
+	Owner         string    // --owner user[:group]
+	Perm          string    // --perm mode
+	Where         string    // --where expression
+	Now           time.Time // Time relative durations like ChangedWithin: "1h" are measured back from, the start of every search when zero
+}
+
+// Filter decides whether a result is kept.
+type Filter interface {
+	Keep(entry Entry) bool
+}
+
+// FilterFunc adapts a function to the Filter interface.
+type FilterFunc func(entry Entry) bool
+
+func (f FilterFunc) Keep(entry Entry) bool { return f(entry) }
+
+// Options configures a Finder. The zero value finds every entry below the working directory
+// that is neither hidden nor ignored.
+type Options struct {
+	FS            fs.FS    // File system to search, the operating system's when nil
+	Roots         []string // Directories to search, the working directory or the root of FS when empty
+	Pattern       string   // Regex the name must match, everything matches when empty
+	Glob          string   // Glob the name must match, used instead of Pattern when set
+	FullPath      bool     // Match Pattern or Glob against the whole path instead of the base name
+	CaseSensitive bool     // Match case-sensitively; by default only patterns with uppercase letters do
+	IgnoreCase    bool     // Match case-insensitively
+	MaxDepth      int      // Levels below the roots to report: 1 for their direct children, 0 for no limit
+	Threads       int      // Directories read in parallel, at most and by default the number of CPUs
+	Hidden        bool     // Include hidden files and directories
+	Archives      bool     // Search inside zip and tar archives, also as Roots, reporting members as "release.tar.gz!/bin/app"
+	Ignore        IgnoreOptions
+	Filters       FilterOptions
+	ExtraFilters  []Filter // Filters run after the built-in ones, in order
+
+	Contains     string // Regex file contents must match, empty for no content search
+	ShowLines    bool   // Collect the lines matching Contains in Entry.Matches
+	ContextLines int    // Lines of context collected around each match, implies ShowLines
+	MaxFileSize  int64  // Skip files larger than this many bytes when searching contents, 0 for no limit
+
+	Sort bool // Deliver results sorted by path once the search completes
+
+	// OnWarning is called with problems that do not stop a search, like archives that cannot be
+	// read, which are then reported as ordinary files. It may be called concurrently; nil ignores them.
+	OnWarning func(error)
+}
+
+// Finder runs searches with validated options. It can be used for any number of searches,
+// also concurrently.
+type Finder struct {
+	opts          Options
+	fsys          fsys.FS
+	depth         int // Traversal depth, -1 for no limit
+	pattern       string
+	caseSensitive bool
+	content       *search.ContentOptions
+	now           func() time.Time // Clock the searches start by
+}
+
+// New validates the options and returns a Finder for them.
+func New(opts Options) (*Finder, error) {
+	if len(opts.Roots) == 0 {
+		opts.Roots = []string{"."}
+	}
+	if opts.Threads == 0 || opts.Threads > runtime.NumCPU() {
+		opts.Threads = runtime.NumCPU()
+	}
+
+	f := &Finder{opts: opts, fsys: fsys.OS, now: time.Now}
+	if opts.FS != nil {
+		f.fsys = fsys.FromFS(opts.FS)
+	}
+
+	// Validate the roots and traversal options up front instead of on every search
+	for _, root := range opts.Roots {
+		if err := traverse.ValidateRoot(f.fsys, root, opts.Archives); err != nil {
+			return nil, err
+		}
+	}
+	if opts.MaxDepth < 0 {
+		return nil, fmt.Errorf("invalid max depth: %d, must be 0 (unlimited) or positive", opts.MaxDepth)
+	}
+	f.depth = opts.MaxDepth - 1
+	if opts.Threads < 0 {
+		return nil, fmt.Errorf("invalid threads: %d, must be 0 (the number of CPUs) or positive", opts.Threads)
+	}
+	if err := utils.ValidateIgnoreFiles(opts.Ignore.Files); err != nil {
+		return nil, err
+	}
+
+	// Resolve the name pattern and its case sensitivity
+	pattern := opts.Pattern
+	if opts.Glob != "" {
+		pattern = opts.Glob
+	} else if pattern == "" {
+		pattern = "." // Matches everything
+	}
+	pattern, err := utils.HandlePattern(pattern, opts.Glob)
+	if err != nil {
+		return nil, fmt.Errorf("error determining pattern: %v", err)
+	}
+	f.pattern = pattern
+	f.caseSensitive, err = utils.ResolveCaseSensitivity(pattern, opts.Glob != "", opts.CaseSensitive, opts.IgnoreCase)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the filters; they are built again for every search, which measures relative times from its start
+	if _, err := f.buildFilters(); err != nil {
+		return nil, err
+	}
+
+	if opts.Contains != "" {
+		contentCaseSensitive, err := utils.ResolveCaseSensitivity(opts.Contains, false, opts.CaseSensitive, opts.IgnoreCase)
+		if err != nil {
+			return nil, err
+		}
+		f.content = &search.ContentOptions{
+			Pattern:       opts.Contains,
+			CaseSensitive: contentCaseSensitive,
+			ShowLines:     opts.ShowLines || opts.ContextLines > 0,
+			Context:       opts.ContextLines,
+			MaxFileSize:   opts.MaxFileSize,
+		}
+		if _, err := search.CompileContentPattern(*f.content); err != nil {
+			return nil, fmt.Errorf("error during content search: %v", err)
+		}
+	}
+
+	return f, nil
+}
+
+// Find runs a search and yields its results. Errors reading the file system are yielded once
+// the search has finished; stopping the loop early cancels the search.
+func (f *Finder) Find(ctx context.Context) iter.Seq2[Entry, error] {
+	return func(yield func(Entry, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		results, errc := f.Stream(ctx)
+		for entry := range results {
+			if !yield(entry, nil) {
+				return
+			}
+		}
+		if err := <-errc; err != nil {
+			yield(Entry{}, err)
+		}
+	}
+}
+
+// Stream starts a search and sends its results on the returned channel, which is closed once
+// the search completes or ctx is cancelled. The error channel then receives the first error
+// reading the file system, if any, and is closed.
+func (f *Finder) Stream(ctx context.Context) (<-chan Entry, <-chan error) {
+	// Cancelling once the results run out also stops the stages already started when a later one
+	// cannot be set up, as the search then delivers no results
+	ctx, cancel := context.WithCancel(ctx)
+	results, errc := f.stream(ctx)
+	entries := make(chan Entry, cap(results))
+	go func() {
+		defer cancel()
+		defer close(entries)
+		for entry := range results {
+			select {
+			case entries <- newEntry(entry):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return entries, errc
+}
+
+// stream runs the search pipeline on traversal entries.
+func (f *Finder) stream(ctx context.Context) (<-chan traverse.Entry, <-chan error) {
+	filters, err := f.buildFilters()
+	if err != nil {
+		return failed(err)
+	}
+
+	// Every stage below streams into the next one; cancelling ctx stops the whole pipeline
+	results, traversalErr, err := traverse.TraverseAndValidate(ctx, f.fsys, f.opts.Roots, f.depth, f.opts.Threads, f.opts.Hidden, f.opts.Archives, ignore.Options(f.opts.Ignore), f.opts.OnWarning)
+	if err != nil {
+		return failed(err)
+	}
+
+	// Match names against the pattern
+	results, err = search.SearchPattern(ctx, f.pattern, results, f.opts.Threads, f.opts.Glob != "", f.caseSensitive, f.opts.FullPath)
+	if err != nil {
+		return failed(fmt.Errorf("error during search: %v", err))
+	}
+
+	// Apply the filters
+	if len(filters) > 0 {
+		results = filter.FilterResults(ctx, results, filters)
+	}
+
+	// Search file contents of the remaining results
+	if f.content != nil {
+		results, err = search.SearchContents(ctx, results, f.opts.Threads, *f.content)
+		if err != nil {
+			return failed(fmt.Errorf("error during content search: %v", err))
+		}
+	}
+
+	// Sort the results once the search completes
+	if f.opts.Sort {
+		results = output.SortResults(ctx, results)
+	}
+
+	return results, traversalErr
+}
+
+// buildFilters builds the filters in their registered order, then the caller's own. Durations
+// like ChangedWithin: "1h" are measured back from the time of the call, unless Now is set.
+func (f *Finder) buildFilters() ([]filter.Filter, error) {
+	opts := filter.Options(f.opts.Filters)
+	if opts.Now.IsZero() {
+		opts.Now = f.now()
+	}
+	filters, err := filter.Build(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error during filtering: %v", err)
+	}
+	for _, extra := range f.opts.ExtraFilters {
+		filters = append(filters, filter.Func(func(entry traverse.Entry) bool {
+			return extra.Keep(newEntry(entry))
+		}))
+	}
+	return filters, nil
+}
+
+// failed returns a closed result channel and an error channel holding err.
+func failed(err error) (<-chan traverse.Entry, <-chan error) {
+	results := make(chan traverse.Entry)
+	close(results)
+	errc := make(chan error, 1)
+	errc <- err
+	close(errc)
+	return results, errc
+}
@@ -0,0 +1,177 @@
+package gofs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// TestRelativeTimesPerSearch checks that a reused Finder measures relative times from the start
+// of every search rather than from when it was built.
+func TestRelativeTimesPerSearch(t *testing.T) {
+	built := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	files := fstest.MapFS{
+		// Within the last hour when the Finder is built, but not anymore a second later
+		"edge.txt":  {ModTime: built.Add(-time.Hour + 500*time.Millisecond)},
+		"fresh.txt": {ModTime: built},
+	}
+
+	for _, filters := range []FilterOptions{
+		{ChangedWithin: "1h"},
+		{Where: "mtime<1h"},
+	} {
+		finder, err := New(Options{FS: files, Filters: filters})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tt := range []struct {
+			now  time.Time
+			want []string
+		}{
+			{built, []string{"edge.txt", "fresh.txt"}},
+			{built.Add(time.Second), []string{"fresh.txt"}},
+		} {
+			finder.now = func() time.Time { return tt.now }
+			if got := findPaths(t, finder); !slices.Equal(got, tt.want) {
+				t.Errorf("%+v at %v: got %q, want %q", filters, tt.now, got, tt.want)
+			}
+		}
+	}
+}
+
+// TestFilterNow checks that FilterOptions.Now fixes the time relative durations are measured from.
+func TestFilterNow(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	files := fstest.MapFS{
+		"old.txt": {ModTime: now.Add(-2 * time.Hour)},
+		"new.txt": {ModTime: now.Add(-time.Minute)},
+	}
+	finder, err := New(Options{FS: files, Filters: FilterOptions{ChangedWithin: "1h", Now: now}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := findPaths(t, finder), []string{"new.txt"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// findPaths runs a search and returns the sorted paths it found.
+func findPaths(t *testing.T, finder *Finder) []string {
+	t.Helper()
+
+	var paths []string
+	for entry, err := range finder.Find(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, entry.Path)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func TestThreads(t *testing.T) {
+	files := fstest.MapFS{"a.txt": {}}
+	if _, err := New(Options{FS: files, Threads: runtime.NumCPU() + 8}); err != nil {
+		t.Errorf("more threads than CPUs: unexpected error: %v", err)
+	}
+	if _, err := New(Options{FS: files, Threads: -1}); err == nil {
+		t.Error("negative threads: expected an error")
+	}
+}
+
+// TestEntry checks that results and the entries passed to ExtraFilters can be read on their own.
+func TestEntry(t *testing.T) {
+	files := fstest.MapFS{
+		"dir/a.txt": {Data: []byte("needle\n")},
+		"dir/b.txt": {Data: []byte("hay\n")},
+	}
+	finder, err := New(Options{
+		FS:        files,
+		Contains:  "needle",
+		ShowLines: true,
+		ExtraFilters: []Filter{FilterFunc(func(entry Entry) bool {
+			info, err := entry.Info()
+			return err == nil && !info.IsDir()
+		})},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []Entry
+	for entry, err := range finder.Find(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d results, want dir/a.txt only", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.Path != "dir/a.txt" || entry.Name() != "a.txt" || entry.IsDir() {
+		t.Errorf("got %q named %q, want the file dir/a.txt", entry.Path, entry.Name())
+	}
+	if want := []LineMatch{{Number: 1, Text: "needle"}}; !slices.Equal(entry.Matches, want) {
+		t.Errorf("got matches %+v, want %+v", entry.Matches, want)
+	}
+	if info, err := entry.Info(); err != nil || info.Size() != int64(len("needle\n")) {
+		t.Errorf("Info() = %v, %v, want the file information of dir/a.txt", info, err)
+	}
+	file, err := entry.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if data, err := io.ReadAll(file); err != nil || string(data) != "needle\n" {
+		t.Errorf("read %q, %v, want the contents of dir/a.txt", data, err)
+	}
+}
+
+func TestInvalidContentPattern(t *testing.T) {
+	if _, err := New(Options{FS: fstest.MapFS{}, Contains: "("}); err == nil {
+		t.Error("expected an error for an invalid content pattern")
+	}
+}
+
+// TestStreamSetupFailure checks that a search whose later stages cannot be set up stops the
+// traversal it already started instead of leaking its goroutines.
+func TestStreamSetupFailure(t *testing.T) {
+	files := fstest.MapFS{}
+	for i := 0; i < 100; i++ {
+		files[fmt.Sprintf("dir%d/file.txt", i)] = &fstest.MapFile{Data: []byte("needle\n")}
+	}
+	finder, err := New(Options{FS: files, Contains: "needle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	finder.content.Pattern = "(" // Only fails once the traversal has started
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 5; i++ {
+		results, errc := finder.Stream(context.Background())
+		for range results {
+			t.Fatal("expected no results")
+		}
+		if err := <-errc; err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+
+	// Stopped goroutines take a moment to exit
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines before the searches, %d after", before, after)
+	}
+}
@@ -0,0 +1,88 @@
+package gofs
+
+import (
+	"gofs/internal/filter"
+	"gofs/internal/output"
+	"gofs/internal/traverse"
+)
+
+// FilterBuilder creates a filter from the options of a search, reporting false when they do not ask for it.
+type FilterBuilder func(opts FilterOptions) (Filter, bool, error)
+
+// RegisterFilter adds a filter to every search, the gofs command's included, usually from an init
+// function. Filters run by ascending priority, then by name: the built-in ones are spaced ten apart
+// from 10 (see RegisteredFilters), and filters that only look at the path should come before those
+// that read file information. Options.ExtraFilters run after all of them. Registering a name
+// again replaces the earlier filter.
+func RegisterFilter(name string, priority int, build FilterBuilder) {
+	filter.Register(name, priority, func(opts filter.Options) (filter.Filter, bool, error) {
+		f, ok, err := build(FilterOptions(opts))
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		return filter.Func(func(entry traverse.Entry) bool {
+			return f.Keep(newEntry(entry))
+		}), true, nil
+	})
+}
+
+// RegisteredFilters lists the names of the registered filters in the order they run.
+func RegisteredFilters() []string {
+	return filter.Registered()
+}
+
+// Line is the line the gofs command prints for a result, colored and linked by the command itself.
+type Line struct {
+	Info string // Metadata printed before the path, e.g. long list columns
+	Path string // Path of the result, empty to print Text alone
+	Text string // Text printed right after the path, e.g. a matched line
+	Link string // URL the path links to in terminals, empty for none
+}
+
+// FormatOptions holds the output flags of the gofs command.
+type FormatOptions struct {
+	Format       string // --format: empty for text, json or ndjson
+	AbsolutePath bool   // -A
+	LongList     bool   // -l
+	Hyperlink    bool   // -L, resolved from its mode
+	Quote        string // --quote: shell, c, literal or empty for literal
+	Template     string // --template
+	GoTemplate   string // --go-template
+}
+
+// Formatter fills in or rewrites the printed line of a result. It returns false to drop the result.
+type Formatter interface {
+	Format(entry Entry, line *Line) bool
+}
+
+// FormatterFunc adapts a function to the Formatter interface.
+type FormatterFunc func(entry Entry, line *Line) bool
+
+func (f FormatterFunc) Format(entry Entry, line *Line) bool { return f(entry, line) }
+
+// FormatterBuilder creates a formatter from the output flags, reporting false when they do not ask for it.
+type FormatterBuilder func(opts FormatOptions) (Formatter, bool, error)
+
+// RegisterFormatter adds a formatter to the text output of the gofs command, usually from an init
+// function of a program that runs it. Formatters run by ascending priority, then by name, so a
+// formatter sees the line as left by the ones before it: the built-in ones are spaced ten apart
+// from 10 (see RegisteredFormatters). Registering a name again replaces the earlier formatter.
+func RegisterFormatter(name string, priority int, build FormatterBuilder) {
+	output.RegisterFormatter(name, priority, func(opts output.Options) (output.Formatter, bool, error) {
+		f, ok, err := build(FormatOptions(opts))
+		if err != nil || !ok {
+			return nil, false, err
+		}
+		return output.FormatterFunc(func(entry traverse.Entry, line *output.Line) bool {
+			l := Line{Info: line.Info, Path: line.Path, Text: line.Text, Link: line.Link}
+			ok := f.Format(newEntry(entry), &l)
+			line.Info, line.Path, line.Text, line.Link = l.Info, l.Path, l.Text, l.Link
+			return ok
+		}), true, nil
+	})
+}
+
+// RegisteredFormatters lists the names of the registered formatters in the order they run.
+func RegisteredFormatters() []string {
+	return output.RegisteredFormatters()
+}
@@ -0,0 +1,86 @@
+package gofs
+
+import (
+	"context"
+	"gofs/internal/output"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRegisterFilter(t *testing.T) {
+	// Active only when excluding *.skip, so other tests are left alone
+	RegisterFilter("test-skip-generated", 15, func(opts FilterOptions) (Filter, bool, error) {
+		return FilterFunc(func(entry Entry) bool {
+			return !strings.HasPrefix(entry.Name(), "generated_")
+		}), slices.Contains(opts.Excludes, "*.skip"), nil
+	})
+
+	names := RegisteredFilters()
+	if i := slices.Index(names, "test-skip-generated"); i < 1 || names[i-1] != "exclude" || names[i+1] != "extension" {
+		t.Errorf("registered filters %q, want test-skip-generated between exclude and extension", names)
+	}
+
+	files := fstest.MapFS{
+		"generated_a.go": {},
+		"b.go":           {},
+		"c.skip":         {},
+	}
+	finder, err := New(Options{FS: files, Filters: FilterOptions{Excludes: []string{"*.skip"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for entry, err := range finder.Find(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, entry.Path)
+	}
+	if want := []string{"b.go"}; !slices.Equal(paths, want) {
+		t.Errorf("got %q, want %q", paths, want)
+	}
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	// Active only with -l, so other tests are left alone
+	RegisterFormatter("test-size-suffix", 25, func(opts FormatOptions) (Formatter, bool, error) {
+		return FormatterFunc(func(entry Entry, line *Line) bool {
+			info, err := entry.Info()
+			if err != nil {
+				return false
+			}
+			line.Info = "" // Runs after the long list columns
+			line.Text = " (" + strings.Repeat("#", int(info.Size())) + ")"
+			return true
+		}), opts.LongList, nil
+	})
+
+	names := RegisteredFormatters()
+	if i := slices.Index(names, "test-size-suffix"); i < 1 || names[i-1] != "long-list" || names[i+1] != "quote" {
+		t.Errorf("registered formatters %q, want test-size-suffix between long-list and quote", names)
+	}
+
+	formatters, err := output.BuildFormatters(output.Options{LongList: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := fstest.MapFS{"a.txt": {Data: []byte("abc")}}
+	finder, err := New(Options{FS: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, errc := finder.stream(context.Background())
+
+	var lines []Line
+	for line := range output.FormatResults(context.Background(), results, formatters) {
+		lines = append(lines, Line{Info: line.Info, Path: line.Path, Text: line.Text, Link: line.Link})
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if want := []Line{{Path: "a.txt", Text: " (###)"}}; !slices.Equal(lines, want) {
+		t.Errorf("got %+v, want %+v", lines, want)
+	}
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"errors"
+	"unicode"
+)
+
+// ResolveCaseSensitivity decides whether the pattern is matched case-sensitively.
+// -S and -i force either mode; otherwise smart case applies and the search is only
+// case-sensitive when the pattern contains an uppercase letter.
+func ResolveCaseSensitivity(pattern string, isGlob bool, caseSensitive bool, ignoreCase bool) (bool, error) {
+	if caseSensitive && ignoreCase {
+		return false, errors.New("--case-sensitive and --ignore-case cannot be used together")
+	}
+	if caseSensitive {
+		return true, nil
+	}
+	if ignoreCase {
+		return false, nil
+	}
+	return HasUppercase(pattern, isGlob), nil
+}
+
+// HasUppercase reports whether a pattern contains an uppercase literal.
+// In regex patterns, escape sequences such as \S, \W or \p{Lu} are not literals and are skipped.
+func HasUppercase(pattern string, isGlob bool) bool {
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '\\' && !isGlob && i+1 < len(runes) {
+			i++
+			// Skip the name of Unicode classes like \p{Lu} or \pL
+			if runes[i] == 'p' || runes[i] == 'P' {
+				if i+1 < len(runes) && runes[i+1] == '{' {
+					for i < len(runes) && runes[i] != '}' {
+						i++
+					}
+				} else {
+					i++
+				}
+			}
+			continue
+		}
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
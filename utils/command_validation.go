@@ -1,7 +1,6 @@
 package utils
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -9,38 +8,31 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// ValidateCommand ensures valid pattern, pathname, and flag usage
+// ValidateCommand ensures a valid pattern and flag usage
 func ValidateCommand(cmd *cobra.Command, args []string) error {
 	// Step 1: Default arguments
 	pattern := "."
-	pathname := ""
 
-	// Step 2: Parse arguments
-	if len(args) > 0 {
+	// Step 2: Parse the pattern (with --glob, every positional argument is a pathname)
+	globPattern, _ := cmd.Flags().GetString("glob")
+	if globPattern != "" {
+		pattern = globPattern
+	} else if len(args) > 0 {
 		pattern = args[0]
 	}
-	if len(args) > 1 {
-		pathname = args[1]
-	}
 
-	// Step 3: Validate pathname is not mistakenly provided in pattern
-	if len(args) == 1 && strings.Contains(pattern, "/") {
-		return errors.New("pathname has been provided in the pattern parameter")
+	// Step 3: A base name never contains a separator, so such patterns need --full-path
+	fullPath, _ := cmd.Flags().GetBool("full-path")
+	if !fullPath && strings.Contains(pattern, "/") {
+		return fmt.Errorf("the pattern %q contains a path separator and can only match with --full-path (-p); to search inside a directory, pass it as [pathname]", pattern)
 	}
 
-	// Step 4: Validate pathname if provided
-	if len(args) == 2 {
-		// Manually check if the pathname exists
-		if _, err := os.Stat(pathname); os.IsNotExist(err) {
-			return fmt.Errorf("pathname doesn't exist in the current directory: %s", pathname)
-		}
-	}
+	// The pathnames are validated by the search itself, which also accepts archives with --search-archives
 
-	// Step 5: Set pattern and pathname in flags
+	// Step 4: Set pattern in flags
 	cmd.Flags().Set("pattern", pattern)
-	cmd.Flags().Set("pathname", pathname)
 
-	// Step 6: Validate flags (e.g., help/version logic)
+	// Step 5: Validate flags (e.g., help/version logic)
 	if err := validateFlags(cmd); err != nil {
 		return err
 	}
@@ -0,0 +1,13 @@
+package utils
+
+import "fmt"
+
+// ValidateFileType checks if the file type passed to --file-type is supported.
+func ValidateFileType(fileType string) error {
+	switch fileType {
+	case "file", "dir", "symlink", "socket", "pipe", "char-device", "block-device", "executable", "empty", "broken-symlink":
+		return nil
+	default:
+		return fmt.Errorf("invalid file type: %s, expected file, dir, symlink, socket, pipe, char-device, block-device, executable, empty or broken-symlink", fileType)
+	}
+}
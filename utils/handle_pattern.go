@@ -2,7 +2,7 @@ package utils
 
 import (
 	"fmt"
-	"path/filepath"
+	"gofs/internal/glob"
 	"regexp"
 )
 
@@ -16,8 +16,8 @@ func HandlePattern(pattern string, globPattern string) (string, error) {
 	// Case 2: Handle glob pattern
 	if globPattern != "" {
 		// Validate the glob pattern
-		if !IsValidGlob(globPattern) {
-			return "", fmt.Errorf("invalid glob pattern: %s", globPattern)
+		if err := ValidateGlob(globPattern); err != nil {
+			return "", err
 		}
 		// Return the globPattern as the effective pattern
 		return globPattern, nil
@@ -33,14 +33,8 @@ func HandlePattern(pattern string, globPattern string) (string, error) {
 	return pattern, nil
 }
 
-// isValidGlob validates a glob pattern for correctness.
-func IsValidGlob(pattern string) bool {
-	// Minimal validation for glob patterns
-	if pattern == "" {
-		return false
-	}
-
-	// Use filepath.Match to validate the pattern
-	matches, err := filepath.Glob(pattern)
-	return err == nil && len(matches) >= 0
+// ValidateGlob checks the syntax of a glob pattern without touching the filesystem.
+func ValidateGlob(pattern string) error {
+	_, err := glob.Compile(pattern)
+	return err
 }
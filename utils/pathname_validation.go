@@ -1,40 +1,46 @@
 package utils
 
 import (
-	"os"
-	"strings"
+	"errors"
+	"fmt"
+	"gofs/internal/fsys"
+	"io"
+	"io/fs"
 )
 
-// ValidatePathname filters traversed paths based on the provided pathname.
-// Returns a list of valid paths and a boolean indicating if any matches were found.
-func ValidatePathname(traversedPaths []string, pathname string) ([]string, bool) {
-	var validPaths []string
-
-	// Normalize the pathname (remove trailing slashes)
-	isRoot := pathname == "."
-	normalizedPathname := strings.TrimSuffix(pathname, string(os.PathSeparator))
-
-	for _, path := range traversedPaths {
-		// Check if the path exists
-		info, err := os.Stat(path)
-		if err != nil {
-			continue // Skip invalid paths
+// ValidatePathnameIn checks that the pathname used as a search root exists on the file system,
+// is a directory and can be read. Both absolute and relative pathnames are accepted.
+func ValidatePathnameIn(fileSystem fsys.FS, pathname string) error {
+	info, err := fileSystem.Stat(pathname)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("pathname doesn't exist: %s", pathname)
 		}
+		if errors.Is(err, fs.ErrPermission) {
+			return fmt.Errorf("permission denied: %s", pathname)
+		}
+		return fmt.Errorf("invalid pathname %s: %v", pathname, err)
+	}
 
-		// Handle directories
-		if info.IsDir() {
-			// Match directory name or substring with the provided pathname
-			if isRoot || strings.Contains(path, normalizedPathname) {
-				validPaths = append(validPaths, path+string(os.PathSeparator))
-			}
-			continue
+	if !info.IsDir() {
+		return fmt.Errorf("pathname is not a directory: %s", pathname)
+	}
+
+	// Make sure the directory can actually be listed
+	dir, err := fileSystem.Open(pathname)
+	if err != nil {
+		if errors.Is(err, fs.ErrPermission) {
+			return fmt.Errorf("permission denied: %s", pathname)
 		}
+		return fmt.Errorf("invalid pathname %s: %v", pathname, err)
+	}
+	defer dir.Close()
 
-		// Handle files
-		if strings.Contains(path, normalizedPathname) {
-			validPaths = append(validPaths, path)
+	if d, ok := dir.(fs.ReadDirFile); ok {
+		if _, err := d.ReadDir(1); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("cannot read directory %s: %v", pathname, err)
 		}
 	}
 
-	return validPaths, len(validPaths) > 0
+	return nil
 }
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits maps size suffixes to their multipliers. SI units (k, M, G, T) are powers of 1000,
+// IEC units (Ki, Mi, Gi, Ti) are powers of 1024. Suffixes are matched case-insensitively.
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"kb":  1000,
+	"ki":  1 << 10,
+	"kib": 1 << 10,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"mi":  1 << 20,
+	"mib": 1 << 20,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"gi":  1 << 30,
+	"gib": 1 << 30,
+	"t":   1000 * 1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"ti":  1 << 40,
+	"tib": 1 << 40,
+}
+
+// ParseSize converts a size like "512", "10k", "4Ki" or "1.5MB" into bytes.
+func ParseSize(size string) (int64, error) {
+	size = strings.TrimSpace(size)
+
+	// Split the number from the unit suffix
+	i := 0
+	for i < len(size) && (size[i] >= '0' && size[i] <= '9' || size[i] == '.') {
+		i++
+	}
+	number, unit := size[:i], strings.ToLower(size[i:])
+
+	multiplier, ok := sizeUnits[unit]
+	if !ok || number == "" {
+		return 0, fmt.Errorf("invalid size: %q, expected a number with an optional unit like 10k, 4Ki or 1M", size)
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size: %q, expected a number with an optional unit like 10k, 4Ki or 1M", size)
+	}
+
+	bytes := value * float64(multiplier)
+	if bytes > math.MaxInt64 {
+		return 0, fmt.Errorf("invalid size: %q is too large", size)
+	}
+	return int64(bytes), nil
+}
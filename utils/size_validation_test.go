@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		size string
+		want int64
+	}{
+		{"0", 0},
+		{"512", 512},
+		{"512b", 512},
+		{"10k", 10_000},
+		{"10K", 10_000},
+		{"10kB", 10_000},
+		{"4Ki", 4096},
+		{"4KiB", 4096},
+		{"4kib", 4096},
+		{"1.5M", 1_500_000},
+		{"1.5MB", 1_500_000},
+		{"1Mi", 1 << 20},
+		{"2G", 2_000_000_000},
+		{"2Gi", 2 << 30},
+		{"1T", 1_000_000_000_000},
+		{"1Ti", 1 << 40},
+		{" 7k ", 7000},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.size)
+		if err != nil {
+			t.Errorf("ParseSize(%q): unexpected error: %v", tt.size, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestParseSizeErrors(t *testing.T) {
+	tests := []struct {
+		size string
+		want string // Part of the error message
+	}{
+		{"", "expected a number"},
+		{"k", "expected a number"},
+		{"10x", "expected a number"},
+		{"10 k", "expected a number"},
+		{"1.2.3k", "expected a number"},
+		{"-5", "expected a number"},
+		{"10000000Ti", "too large"},
+	}
+	for _, tt := range tests {
+		_, err := ParseSize(tt.size)
+		if err == nil {
+			t.Errorf("ParseSize(%q): expected an error", tt.size)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseSize(%q) error = %q, want it to contain %q", tt.size, err, tt.want)
+		}
+	}
+}
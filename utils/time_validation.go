@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationUnits maps duration suffixes to their length. Months are left out on purpose,
+// "m" is minutes as in Go durations.
+var durationUnits = map[string]time.Duration{
+	"s":       time.Second,
+	"sec":     time.Second,
+	"secs":    time.Second,
+	"second":  time.Second,
+	"seconds": time.Second,
+	"m":       time.Minute,
+	"min":     time.Minute,
+	"mins":    time.Minute,
+	"minute":  time.Minute,
+	"minutes": time.Minute,
+	"h":       time.Hour,
+	"hour":    time.Hour,
+	"hours":   time.Hour,
+	"d":       24 * time.Hour,
+	"day":     24 * time.Hour,
+	"days":    24 * time.Hour,
+	"w":       7 * 24 * time.Hour,
+	"week":    7 * 24 * time.Hour,
+	"weeks":   7 * 24 * time.Hour,
+	"y":       365 * 24 * time.Hour,
+	"year":    365 * 24 * time.Hour,
+	"years":   365 * 24 * time.Hour,
+}
+
+// dateLayouts are the absolute date formats accepted by the time filters. Dates without
+// a zone are read in the local time zone.
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+	time.RFC3339Nano,
+}
+
+// ValidateTimeField checks if the timestamp passed to --time-field is supported.
+func ValidateTimeField(field string) error {
+	switch field {
+	case "mtime", "atime", "ctime", "btime":
+		return nil
+	default:
+		return fmt.Errorf("invalid time field: %s, expected mtime, atime, ctime or btime", field)
+	}
+}
+
+// ParsePointInTime converts a duration like "2d" or "1h30m" into the moment that long before now,
+// and reads dates like "2024-01-01", "2024-01-01 15:04:05", RFC 3339 timestamps and "@<unix seconds>" as is.
+func ParsePointInTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("invalid time: empty value, expected a duration like 2d or 10h30m, or a date like 2024-01-01")
+	}
+
+	if seconds, ok := strings.CutPrefix(value, "@"); ok {
+		unix, err := strconv.ParseInt(seconds, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time: %q, expected unix seconds after @", value)
+		}
+		return time.Unix(unix, 0), nil
+	}
+
+	if duration, err := ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time: %q, expected a duration like 2d or 10h30m, or a date like 2024-01-01 or 2024-01-01T15:04:05Z", value)
+}
+
+// ParseDuration parses a sequence of numbers with units like "2d", "1w3d" or "10 hours".
+// Besides Go's units it understands days, weeks and years. Units ignore case and there is no
+// month unit, so "1M" is one minute, not one month.
+func ParseDuration(value string) (time.Duration, error) {
+	s := strings.ReplaceAll(value, " ", "")
+	if s == "" {
+		return 0, fmt.Errorf("invalid duration: %q", value)
+	}
+
+	var total time.Duration
+	for s != "" {
+		// Split the number from its unit
+		i := 0
+		for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+			i++
+		}
+		j := i
+		for j < len(s) && (s[j] < '0' || s[j] > '9') && s[j] != '.' {
+			j++
+		}
+		number, unit := s[:i], strings.ToLower(s[i:j])
+		s = s[j:]
+
+		multiplier, ok := durationUnits[unit]
+		if !ok || number == "" {
+			return 0, fmt.Errorf("invalid duration: %q, expected a number with a unit like 30s, 10m, 2h, 3d, 1w or 1y", value)
+		}
+		n, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration: %q, expected a number with a unit like 30s, 10m, 2h, 3d, 1w or 1y", value)
+		}
+		total += time.Duration(n * float64(multiplier))
+	}
+
+	return total, nil
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"30s", 30 * time.Second},
+		{"10m", 10 * time.Minute},
+		{"1M", time.Minute}, // Minutes, there is no month unit
+		{"2h", 2 * time.Hour},
+		{"10h30m", 10*time.Hour + 30*time.Minute},
+		{"3d", 72 * time.Hour},
+		{"1w3d", 10 * 24 * time.Hour},
+		{"1y", 365 * 24 * time.Hour},
+		{"1.5h", 90 * time.Minute},
+		{"10 hours", 10 * time.Hour},
+		{"2 Days 1 min", 48*time.Hour + time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.value)
+		if err != nil {
+			t.Errorf("ParseDuration(%q): unexpected error: %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "   ", "10", "d", "2x", "1mo", "1.2.3h", "-2d"} {
+		if got, err := ParseDuration(value); err == nil {
+			t.Errorf("ParseDuration(%q) = %v, expected an error", value, got)
+		}
+	}
+}
+
+func TestParsePointInTime(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2d", now.Add(-48 * time.Hour)},
+		{"10h30m", now.Add(-10*time.Hour - 30*time.Minute)},
+		{"2024-01-01", time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)},
+		{"2024-01-01 15:04", time.Date(2024, 1, 1, 15, 4, 0, 0, time.Local)},
+		{"2024-01-01 15:04:05", time.Date(2024, 1, 1, 15, 4, 5, 0, time.Local)},
+		{"2024-01-01T15:04", time.Date(2024, 1, 1, 15, 4, 0, 0, time.Local)},
+		{"2024-01-01T15:04:05", time.Date(2024, 1, 1, 15, 4, 5, 0, time.Local)},
+		{"2024-01-01T15:04:05Z", time.Date(2024, 1, 1, 15, 4, 5, 0, time.UTC)},
+		{"2024-01-01T15:04:05+02:00", time.Date(2024, 1, 1, 13, 4, 5, 0, time.UTC)},
+		{"2024-01-01T15:04:05.5Z", time.Date(2024, 1, 1, 15, 4, 5, 500_000_000, time.UTC)},
+		{"@0", time.Unix(0, 0)},
+		{"@1700000000", time.Unix(1_700_000_000, 0)},
+		{" 2d ", now.Add(-48 * time.Hour)},
+	}
+	for _, tt := range tests {
+		got, err := ParsePointInTime(tt.value, now)
+		if err != nil {
+			t.Errorf("ParsePointInTime(%q): unexpected error: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParsePointInTime(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParsePointInTimeErrors(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  string // Part of the error message
+	}{
+		{"", "empty value"},
+		{"@", "expected unix seconds after @"},
+		{"@soon", "expected unix seconds after @"},
+		{"yesterday", "expected a duration like 2d"},
+		{"2024-13-01", "expected a duration like 2d"},
+		{"01/02/2024", "expected a duration like 2d"},
+	}
+	for _, tt := range tests {
+		_, err := ParsePointInTime(tt.value, now)
+		if err == nil {
+			t.Errorf("ParsePointInTime(%q): expected an error", tt.value)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParsePointInTime(%q) error = %q, want it to contain %q", tt.value, err, tt.want)
+		}
+	}
+}